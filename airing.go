@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// weekdayNames nombres de los días para agrupar los próximos episodios
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "Lunes",
+	time.Tuesday:   "Martes",
+	time.Wednesday: "Miércoles",
+	time.Thursday:  "Jueves",
+	time.Friday:    "Viernes",
+	time.Saturday:  "Sábado",
+	time.Sunday:    "Domingo",
+}
+
+// AiringAnime serie en emisión con su último episodio y la fecha del próximo
+type AiringAnime struct {
+	Anime
+	LatestEpisode int    `json:"latest_episode,omitempty"`
+	NextEpisode   string `json:"next_episode,omitempty"` // AAAA-MM-DD
+}
+
+// airingAnimes extrae la lista de series en emisión de la portada
+func (p *selectorProfile) airingAnimes(doc *goquery.Document) []Anime {
+	var animesList []Anime
+
+	if p.Airing.Item == "" {
+		return nil
+	}
+
+	doc.Find(p.Airing.Item).Each(func(i int, s *goquery.Selection) {
+		animeLink := s.Find(p.Airing.Link).First()
+		href, exists := animeLink.Attr("href")
+
+		// El tipo va dentro del enlace junto al nombre: "One Piece <span>Anime</span>"
+		animeType := selectionText(animeLink, p.Airing.Type)
+		animeName := strings.Join(strings.Fields(animeLink.Text()), " ")
+		if animeType != "" {
+			animeName = strings.TrimSpace(strings.TrimSuffix(animeName, animeType))
+		}
+
+		if exists && animeName != "" {
+			animesList = append(animesList, Anime{
+				Name: animeName,
+				Link: href,
+				Type: normalizeAnimeType(animeType),
+			})
+		}
+	})
+
+	return animesList
+}
+
+// getAiringAnimes obtiene las series en emisión desde la portada de AnimeFLV
+func getAiringAnimes() ([]Anime, error) {
+	var animesList []Anime
+
+	// La portada cambia a cada rato: no se reutiliza la copia en caché
+	pageCache.Delete(urlBase + "/")
+
+	err := pageFetcher.fetch(urlBase+"/", func(doc *goquery.Document) bool {
+		animesList = selectors.airingAnimes(doc)
+		return len(animesList) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return animesList, nil
+}
+
+// getAiringSchedule completa cada serie en emisión con los datos de su ficha
+func getAiringSchedule(animesList []Anime, verbose bool) []AiringAnime {
+	schedule := make([]AiringAnime, 0, len(animesList))
+
+	for i, anime := range animesList {
+		if verbose {
+			fmt.Printf("Consultando %d/%d: %s", i+1, len(animesList), anime.Name)
+		}
+
+		airing := AiringAnime{Anime: anime}
+
+		details, err := getAnimeDetails(anime.Link)
+		if err != nil {
+			if verbose {
+				fmt.Printf(" ❌ Error: %v\n", err)
+			}
+		} else {
+			airing.LatestEpisode = details.LatestEpisode
+			airing.NextEpisode = details.NextEpisode
+			if airing.Type == "" {
+				airing.Type = details.Type
+			}
+			airing.Cover = details.Cover
+			airing.Rating = details.Score
+			airing.Synopsis = details.Synopsis
+
+			if verbose {
+				fmt.Println(" ✅")
+			}
+		}
+
+		schedule = append(schedule, airing)
+	}
+
+	// Orden semanal: por fecha del próximo episodio y las series sin fecha al final
+	sort.SliceStable(schedule, func(a, b int) bool {
+		dateA, dateB := schedule[a].NextEpisode, schedule[b].NextEpisode
+		if (dateA == "") != (dateB == "") {
+			return dateA != ""
+		}
+		if dateA != dateB {
+			return dateA < dateB
+		}
+		return schedule[a].Name < schedule[b].Name
+	})
+
+	return schedule
+}
+
+// printAiringSchedule muestra las series agrupadas por el día del próximo episodio
+func printAiringSchedule(schedule []AiringAnime) {
+	currentDate := "-"
+
+	for _, airing := range schedule {
+		if airing.NextEpisode != currentDate {
+			currentDate = airing.NextEpisode
+			fmt.Printf("\n%s\n", airingDateLabel(currentDate))
+		}
+
+		line := fmt.Sprintf("   • %s", animeLabel(airing.Anime))
+		if airing.LatestEpisode > 0 {
+			line += fmt.Sprintf(" — último episodio: %d", airing.LatestEpisode)
+		}
+		fmt.Println(line)
+	}
+}
+
+// airingDateLabel título del grupo de un día ("📅 Lunes 2026-10-19")
+func airingDateLabel(date string) string {
+	if date == "" {
+		return "❔ Sin fecha de próximo episodio"
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "📅 " + date
+	}
+
+	return fmt.Sprintf("📅 %s %s", weekdayNames[day.Weekday()], date)
+}
+
+// runAiring implementa el comando `airing`: series en emisión con su último y próximo episodio
+func runAiring(args []string) error {
+	fs := flag.NewFlagSet("airing", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Mostrar el resultado en formato JSON")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	fs.Parse(args)
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+	selectors = profile
+
+	animesList, err := getAiringAnimes()
+	if err != nil {
+		return fmt.Errorf("error obteniendo las series en emisión: %w", err)
+	}
+
+	if len(animesList) == 0 {
+		return fmt.Errorf("no se encontraron series en emisión en la portada")
+	}
+
+	if !*asJSON {
+		fmt.Printf("Series en emisión: %d\n\n", len(animesList))
+	}
+
+	schedule := getAiringSchedule(animesList, !*asJSON)
+
+	if *asJSON {
+		content, err := json.MarshalIndent(schedule, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error generando JSON: %v", err)
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+
+	printAiringSchedule(schedule)
+	pageCache.printStats()
+
+	return nil
+}
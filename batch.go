@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBatchReport nombre del informe que se escribe junto a las exportaciones
+const defaultBatchReport = "batch-report.json"
+
+// slugRegex slug de AnimeFLV escrito tal cual en la lista (ej. shingeki-no-kyojin)
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)+$`)
+
+// episodeRange rango de episodios; To = 0 indica sin límite superior ("20-")
+type episodeRange struct {
+	From int
+	To   int
+}
+
+// episodeRanges lista de rangos de episodios (ej. "1-10,12,20-")
+type episodeRanges []episodeRange
+
+// parseEpisodeRanges interpreta una lista de rangos separados por comas
+func parseEpisodeRanges(text string) (episodeRanges, error) {
+	var ranges episodeRanges
+
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fromText, toText, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(fromText))
+		if err != nil || from < 1 {
+			return nil, fmt.Errorf("rango de episodios inválido: %s", part)
+		}
+
+		to := from
+		if isRange {
+			toText = strings.TrimSpace(toText)
+			to = 0
+			if toText != "" {
+				to, err = strconv.Atoi(toText)
+				if err != nil || to < from {
+					return nil, fmt.Errorf("rango de episodios inválido: %s", part)
+				}
+			}
+		}
+
+		ranges = append(ranges, episodeRange{From: from, To: to})
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("rango de episodios vacío")
+	}
+
+	return ranges, nil
+}
+
+// contains indica si el número de episodio está dentro de algún rango
+func (r episodeRanges) contains(number int) bool {
+	for _, span := range r {
+		if number >= span.From && (span.To == 0 || number <= span.To) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter deja solo los episodios dentro de los rangos (según el número de su nombre)
+func (r episodeRanges) filter(episodes []Episode) []Episode {
+	var filtered []Episode
+
+	for _, episode := range episodes {
+		number := episode.Number
+		if number == 0 {
+			number = parseEpisodeNumber(episode.Name)
+		}
+
+		if r.contains(number) {
+			filtered = append(filtered, episode)
+		}
+	}
+
+	return filtered
+}
+
+// batchEntry línea de la lista de un lote: término de búsqueda o slug con sus opciones
+type batchEntry struct {
+	Line    int
+	Term    string
+	Result  int // número de resultado de la búsqueda a usar (0 = el que coincida o el primero)
+	Options processOptions
+}
+
+// parseBatchFile lee la lista de un lote. Cada línea tiene el formato
+//
+//	término [| [episodios] [--opciones]]
+//
+// donde término es un texto a buscar, un slug, /anime/slug o una URL. Las líneas vacías y
+// las que empiezan con # se ignoran. defaults son las opciones del comando.
+func parseBatchFile(r io.Reader, defaults processOptions) ([]batchEntry, error) {
+	var entries []batchEntry
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		term, optionsText, _ := strings.Cut(line, "|")
+		entry := batchEntry{Line: lineNumber, Term: strings.TrimSpace(term), Options: defaults}
+		if entry.Term == "" {
+			return nil, fmt.Errorf("línea %d: falta el anime", lineNumber)
+		}
+
+		if err := parseBatchOptions(&entry, strings.Fields(optionsText)); err != nil {
+			return nil, fmt.Errorf("línea %d: %v", lineNumber, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo la lista: %v", err)
+	}
+
+	return entries, nil
+}
+
+// parseBatchOptions aplica las opciones de una línea del lote sobre las del comando
+func parseBatchOptions(entry *batchEntry, args []string) error {
+	// El rango de episodios puede ir directamente, sin --episodes
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ranges, err := parseEpisodeRanges(args[0])
+		if err != nil {
+			return err
+		}
+		entry.Options.Episodes = ranges
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("entrada", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	episodes := fs.String("episodes", "", "Episodios a procesar (ej. 1-10,12,20-)")
+	fs.BoolVar(&entry.Options.Franchise, "franchise", entry.Options.Franchise, "Incluir la franquicia completa")
+	fs.IntVar(&entry.Options.FranchiseMax, "franchise-max", entry.Options.FranchiseMax, "Entradas de la franquicia como máximo")
+	fs.StringVar(&entry.Options.DownloadDir, "download", entry.Options.DownloadDir, "Directorio donde descargar los episodios")
+	fs.IntVar(&entry.Result, "result", 0, "Número de resultado de la búsqueda a usar")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("opción desconocida: %s", fs.Arg(0))
+	}
+
+	if *episodes != "" {
+		ranges, err := parseEpisodeRanges(*episodes)
+		if err != nil {
+			return err
+		}
+		entry.Options.Episodes = ranges
+	}
+
+	return nil
+}
+
+// resolveBatchAnime convierte el término de la entrada en un anime concreto, sin preguntar
+func resolveBatchAnime(entry batchEntry, maxPages int) (Anime, error) {
+	term := entry.Term
+
+	// Enlace directo o slug: se toma el título de la ficha
+	isLink := strings.HasPrefix(term, "http://") || strings.HasPrefix(term, "https://") || strings.HasPrefix(term, "/anime/")
+	if isLink || slugRegex.MatchString(term) {
+		animeLink, err := animeLinkFromArg(term)
+		if err != nil {
+			return Anime{}, err
+		}
+
+		details, err := getAnimeDetails(animeLink)
+		if err == nil {
+			return Anime{Name: details.Title, Link: animeLink, Type: details.Type}, nil
+		}
+
+		// Solo se busca como texto si la ficha no existe: un error de red no dice que no sea un slug
+		if isLink || !errors.Is(err, errAnimeNotFound) {
+			return Anime{}, err
+		}
+
+		// No era un slug: se busca como texto
+		term = strings.ReplaceAll(term, "-", " ")
+	}
+
+	animesList, err := searchAnime(term, maxPages)
+	if err != nil {
+		return Anime{}, fmt.Errorf("error en la búsqueda: %w", err)
+	}
+
+	if len(animesList) == 0 {
+		return Anime{}, fmt.Errorf("anime no encontrado: %s", term)
+	}
+
+	if entry.Result > 0 {
+		if entry.Result > len(animesList) {
+			return Anime{}, fmt.Errorf("la búsqueda solo tiene %d resultados", len(animesList))
+		}
+		return animesList[entry.Result-1], nil
+	}
+
+	for _, anime := range animesList {
+		if strings.EqualFold(anime.Name, term) {
+			return anime, nil
+		}
+	}
+
+	return animesList[0], nil
+}
+
+// batchResult resultado de una entrada del lote para el informe
+type batchResult struct {
+	Line     int    `json:"line"`
+	Term     string `json:"term"`
+	Anime    string `json:"anime,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+	processResult
+}
+
+// batchReport informe final del lote
+type batchReport struct {
+	Generated string        `json:"generated"`
+	Total     int           `json:"total"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []batchResult `json:"results"`
+}
+
+// batchClaims animes ya tomados por alguna entrada del lote, para no procesar dos veces el mismo
+type batchClaims struct {
+	mu    sync.Mutex
+	lines map[string]int // enlace del anime -> línea que lo procesa
+}
+
+// claim reserva el anime para la línea; si ya lo tenía otra línea devuelve esa línea y false
+func (c *batchClaims) claim(animeLink string, line int) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if owner, exists := c.lines[animeLink]; exists {
+		return owner, false
+	}
+	c.lines[animeLink] = line
+
+	return line, true
+}
+
+// processBatchEntry procesa una entrada del lote
+func processBatchEntry(entry batchEntry, maxPages int, claims *batchClaims) batchResult {
+	start := time.Now()
+	result := batchResult{Line: entry.Line, Term: entry.Term}
+
+	anime, err := resolveBatchAnime(entry, maxPages)
+	if err == nil {
+		// Dos líneas pueden llevar al mismo anime (ej. slug y búsqueda): escribirían el mismo archivo
+		if owner, ok := claims.claim(anime.Link, entry.Line); !ok {
+			err = fmt.Errorf("anime repetido: ya se procesa en la línea %d", owner)
+		}
+	}
+	if err == nil {
+		result.Anime = anime.Name
+		result.Link = anime.Link
+
+		result.processResult, err = processAnime(anime, entry.Options)
+		if err == nil && entry.Options.DownloadDir != "" {
+			downloadEpisodes(anime.Name, result.episodesList, result.allDownloads, entry.Options.DownloadDir, !entry.Options.Quiet)
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Duration = time.Since(start).Round(time.Second).String()
+
+	return result
+}
+
+// processBatch procesa todas las entradas, de a concurrency a la vez, conservando el orden de la lista
+func processBatch(entries []batchEntry, concurrency, maxPages int) []batchResult {
+	results := make([]batchResult, len(entries))
+
+	jobs := make(chan int)
+	claims := &batchClaims{lines: make(map[string]int)}
+	var printMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for range max(concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				entry := entries[i]
+
+				printMutex.Lock()
+				fmt.Printf("\n▶️  [%d/%d] %s\n", i+1, len(entries), entry.Term)
+				printMutex.Unlock()
+
+				results[i] = processBatchEntry(entry, maxPages, claims)
+
+				printMutex.Lock()
+				if results[i].Error != "" {
+					fmt.Printf("❌ [%d/%d] %s: %s\n", i+1, len(entries), entry.Term, results[i].Error)
+				} else {
+					fmt.Printf("✅ [%d/%d] %s: %d episodios, %d enlaces → %s\n", i+1, len(entries), results[i].Anime, results[i].Processed, results[i].Links, results[i].File)
+				}
+				printMutex.Unlock()
+			}
+		}()
+	}
+
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// printBatchReport muestra el resumen del lote con los fallos al final
+func printBatchReport(report batchReport) {
+	fmt.Printf("\n📊 Resumen del lote: %d entradas, ✅ %d completadas, ❌ %d con errores\n", report.Total, report.Succeeded, report.Failed)
+
+	for _, result := range report.Results {
+		if result.Error == "" {
+			fmt.Printf("   ✅ %s → %s (%d/%d episodios, %d enlaces, %s)\n", result.Anime, result.File, result.Processed, result.Episodes, result.Links, result.Duration)
+		}
+	}
+
+	if report.Failed > 0 {
+		fmt.Println("\n❌ Fallos:")
+		for _, result := range report.Results {
+			if result.Error != "" {
+				fmt.Printf("   • línea %d, %s: %s\n", result.Line, result.Term, result.Error)
+			}
+		}
+	}
+}
+
+// runBatch implementa el comando `batch`: procesa una lista de animes sin interacción
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Uso: ./programa batch [opciones] lista.txt")
+		fmt.Println("Cada línea: término|slug|URL [| episodios] [--episodes 1-10] [--franchise] [--download dir] [--result N]")
+		fs.PrintDefaults()
+	}
+	concurrency := fs.Int("concurrency", 1, "Animes procesados en paralelo")
+	outputDir := fs.String("output", "", "Directorio de las exportaciones (por defecto el actual)")
+	reportFile := fs.String("report", "", "Archivo del informe JSON (por defecto "+defaultBatchReport+" en el directorio de salida)")
+	maxPages := fs.Int("max-pages", defaultMaxSearchPages, "Páginas de resultados de búsqueda a recorrer como máximo (0 = todas)")
+	franchise := fs.Bool("franchise", false, "Incluir la franquicia completa de cada anime")
+	franchiseMax := fs.Int("franchise-max", defaultFranchiseMax, "Entradas de la franquicia a recorrer como máximo (0 = todas)")
+	downloadDir := fs.String("download", "", "Directorio donde descargar los episodios con enlace directo")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no se indicó la lista de animes")
+	}
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+	selectors = profile
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error abriendo la lista: %v", err)
+	}
+	defer file.Close()
+
+	defaults := processOptions{
+		DownloadDir:  *downloadDir,
+		OutputDir:    *outputDir,
+		Franchise:    *franchise,
+		FranchiseMax: *franchiseMax,
+		// En paralelo el progreso por episodio se mezclaría entre animes
+		Quiet: *concurrency > 1,
+	}
+
+	entries, err := parseBatchFile(file, defaults)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("la lista no tiene animes")
+	}
+
+	if *outputDir != "" {
+		if err := os.MkdirAll(*outputDir, 0755); err != nil {
+			return fmt.Errorf("error creando directorio: %v", err)
+		}
+	}
+
+	fmt.Printf("📋 %d animes en la lista\n", len(entries))
+
+	report := batchReport{
+		Generated: time.Now().Format("2006-01-02 15:04:05"),
+		Total:     len(entries),
+		Results:   processBatch(entries, *concurrency, *maxPages),
+	}
+	for _, result := range report.Results {
+		if result.Error != "" {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+
+	printBatchReport(report)
+	pageCache.printStats()
+
+	output := *reportFile
+	if output == "" {
+		output = filepath.Join(*outputDir, defaultBatchReport)
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+	if err := os.WriteFile(output, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("error escribiendo el informe: %v", err)
+	}
+	fmt.Printf("📁 Informe: %s\n", output)
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d de %d animes fallaron", report.Failed, report.Total)
+	}
+
+	return nil
+}
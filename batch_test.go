@@ -0,0 +1,170 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEpisodeRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    episodeRanges
+		wantErr bool
+	}{
+		{name: "rango y episodio suelto", text: "1-3,7", want: episodeRanges{{From: 1, To: 3}, {From: 7, To: 7}}},
+		{name: "sin límite superior", text: "20-", want: episodeRanges{{From: 20, To: 0}}},
+		{name: "espacios y comas de más", text: " 1 - 2 , ,5", want: episodeRanges{{From: 1, To: 2}, {From: 5, To: 5}}},
+		{name: "rango invertido", text: "5-3", wantErr: true},
+		{name: "episodio cero", text: "0", wantErr: true},
+		{name: "texto", text: "uno", wantErr: true},
+		{name: "vacío", text: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEpisodeRanges(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba error, se obtuvo %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEpisodeRanges(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEpisodeRanges(%q) = %+v, se esperaba %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEpisodeRangesFilter(t *testing.T) {
+	ranges, err := parseEpisodeRanges("1-3,7,10-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	episodes := []Episode{
+		{Name: "Naruto Episodio 1"},
+		{Name: "Naruto Episodio 4"},
+		{Name: "Naruto Episodio 7"},
+		{Name: "Naruto Episodio 9"},
+		{Name: "Especial", Number: 12},
+	}
+
+	var got []string
+	for _, episode := range ranges.filter(episodes) {
+		got = append(got, episode.Name)
+	}
+
+	want := []string{"Naruto Episodio 1", "Naruto Episodio 7", "Especial"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filter = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestParseBatchFile(t *testing.T) {
+	defaults := processOptions{OutputDir: "salida", FranchiseMax: 5, Quiet: true}
+
+	list := strings.Join([]string{
+		"# animes de la temporada",
+		"",
+		"naruto",
+		"   ",
+		"  # comentario con sangría",
+		"one-piece | 1-3,7 --franchise",
+		"bleach | --episodes 20- --download descargas --result 2",
+		"https://www3.animeflv.net/anime/naruto | --franchise-max 2",
+	}, "\n")
+
+	entries, err := parseBatchFile(strings.NewReader(list), defaults)
+	if err != nil {
+		t.Fatalf("parseBatchFile: %v", err)
+	}
+
+	want := []batchEntry{
+		{Line: 3, Term: "naruto", Options: defaults},
+		{Line: 6, Term: "one-piece", Options: processOptions{
+			OutputDir: "salida", FranchiseMax: 5, Quiet: true, Franchise: true,
+			Episodes: episodeRanges{{From: 1, To: 3}, {From: 7, To: 7}},
+		}},
+		{Line: 7, Term: "bleach", Result: 2, Options: processOptions{
+			OutputDir: "salida", FranchiseMax: 5, Quiet: true, DownloadDir: "descargas",
+			Episodes: episodeRanges{{From: 20, To: 0}},
+		}},
+		{Line: 8, Term: "https://www3.animeflv.net/anime/naruto", Options: processOptions{
+			OutputDir: "salida", FranchiseMax: 2, Quiet: true,
+		}},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("%d entradas; se esperaban %d: %+v", len(entries), len(want), entries)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(entries[i], want[i]) {
+			t.Errorf("entrada %d = %+v, se esperaba %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestParseBatchFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want string
+	}{
+		{name: "falta el anime", list: "naruto\n | 1-3", want: "línea 2: falta el anime"},
+		{name: "rango inválido", list: "naruto | 3-1", want: "línea 1: rango de episodios inválido: 3-1"},
+		{name: "opción desconocida", list: "# lista\nnaruto | --calidad 1080", want: "línea 2: flag provided but not defined: -calidad"},
+		{name: "argumento suelto", list: "naruto | 1-3 extra", want: "línea 1: opción desconocida: extra"},
+		{name: "valor inválido", list: "naruto | --result dos", want: "línea 1: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseBatchFile(strings.NewReader(tt.list), processOptions{})
+			if err == nil {
+				t.Fatalf("se esperaba error, se obtuvo %+v", entries)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error = %q, se esperaba %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessBatchEntryRejectsDuplicates(t *testing.T) {
+	fetcher := &fixtureFetcher{
+		pages: map[string]string{
+			urlBase + "/browse?q=naruto": loadFixture(t, "browse", "page1.html"),
+		},
+		requests: make(map[string]int),
+	}
+
+	previous := pageFetcher
+	pageFetcher = &fetchChain{fetchers: []Fetcher{fetcher}}
+	t.Cleanup(func() { pageFetcher = previous })
+
+	// La línea 2 ya tomó el anime (ej. escrito como slug): la búsqueda de la línea 5 lleva al mismo
+	claims := &batchClaims{lines: make(map[string]int)}
+	if _, ok := claims.claim("/anime/naruto", 2); !ok {
+		t.Fatal("claim rechazó un anime libre")
+	}
+
+	result := processBatchEntry(batchEntry{Line: 5, Term: "naruto", Result: 1}, 1, claims)
+
+	want := "anime repetido: ya se procesa en la línea 2"
+	if result.Error != want {
+		t.Errorf("error = %q, se esperaba %q", result.Error, want)
+	}
+	if result.Anime != "" || result.episodesList != nil {
+		t.Errorf("la entrada repetida se procesó: %+v", result)
+	}
+
+	if owner, ok := claims.claim("/anime/naruto", 5); ok || owner != 2 {
+		t.Errorf("claim = (%d, %v), se esperaba (2, false)", owner, ok)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// animeGenres géneros de /browse (slug usado en genre[] → nombre mostrado en la web)
+var animeGenres = map[string]string{
+	"accion":               "Acción",
+	"artes-marciales":      "Artes Marciales",
+	"aventura":             "Aventuras",
+	"carreras":             "Carreras",
+	"ciencia-ficcion":      "Ciencia Ficción",
+	"comedia":              "Comedia",
+	"demencia":             "Demencia",
+	"demonios":             "Demonios",
+	"deportes":             "Deportes",
+	"drama":                "Drama",
+	"ecchi":                "Ecchi",
+	"escolares":            "Escolares",
+	"espacial":             "Espacial",
+	"fantasia":             "Fantasía",
+	"harem":                "Harem",
+	"historico":            "Histórico",
+	"infantil":             "Infantil",
+	"josei":                "Josei",
+	"juegos":               "Juegos",
+	"magia":                "Magia",
+	"mecha":                "Mecha",
+	"militar":              "Militar",
+	"misterio":             "Misterio",
+	"musica":               "Música",
+	"parodia":              "Parodia",
+	"policia":              "Policía",
+	"psicologico":          "Psicológico",
+	"recuentos-de-la-vida": "Recuentos de la vida",
+	"romance":              "Romance",
+	"samurai":              "Samurai",
+	"seinen":               "Seinen",
+	"shoujo":               "Shoujo",
+	"shounen":              "Shounen",
+	"sobrenatural":         "Sobrenatural",
+	"superpoderes":         "Superpoderes",
+	"suspenso":             "Suspenso",
+	"terror":               "Terror",
+	"vampiros":             "Vampiros",
+	"yaoi":                 "Yaoi",
+	"yuri":                 "Yuri",
+}
+
+// browseTypes alias aceptados para type[] → valor de AnimeFLV
+var browseTypes = map[string]string{
+	"tv":       "tv",
+	"anime":    "tv",
+	"movie":    "movie",
+	"pelicula": "movie",
+	"special":  "special",
+	"especial": "special",
+	"ova":      "ova",
+}
+
+// browseStatuses alias aceptados para status[] → valor de AnimeFLV
+var browseStatuses = map[string]string{
+	"emision":      "1",
+	"en-emision":   "1",
+	"airing":       "1",
+	"finalizado":   "2",
+	"finished":     "2",
+	"proximamente": "3",
+	"upcoming":     "3",
+}
+
+// browseOrders órdenes aceptados por /browse
+var browseOrders = map[string]string{
+	"default":      "default",
+	"por-defecto":  "default",
+	"updated":      "updated",
+	"actualizado":  "updated",
+	"added":        "added",
+	"agregado":     "added",
+	"title":        "title",
+	"nombre":       "title",
+	"rating":       "rating",
+	"calificacion": "rating",
+}
+
+// BrowseFilter filtros del listado /browse de AnimeFLV; los vacíos no se envían
+type BrowseFilter struct {
+	Query    string
+	Genres   []string
+	Years    []int
+	Types    []string
+	Statuses []string
+	Order    string
+}
+
+// IsEmpty indica si no hay ni texto ni filtros
+func (f BrowseFilter) IsEmpty() bool {
+	return f.Query == "" && len(f.Genres) == 0 && len(f.Years) == 0 &&
+		len(f.Types) == 0 && len(f.Statuses) == 0 && f.Order == ""
+}
+
+// URL arma la URL de la primera página del listado con los filtros normalizados
+func (f BrowseFilter) URL() (string, error) {
+	values := url.Values{}
+
+	for _, genre := range f.Genres {
+		slug := normalizeBrowseValue(genre)
+		if _, exists := animeGenres[slug]; !exists {
+			slug = genreSlugByName(genre)
+			if slug == "" {
+				return "", fmt.Errorf("género desconocido: %s (ver --list-genres)", genre)
+			}
+		}
+		values.Add("genre[]", slug)
+	}
+
+	for _, year := range f.Years {
+		if year < 1900 {
+			return "", fmt.Errorf("año inválido: %d", year)
+		}
+		values.Add("year[]", strconv.Itoa(year))
+	}
+
+	for _, animeType := range f.Types {
+		value, exists := browseTypes[normalizeBrowseValue(animeType)]
+		if !exists {
+			return "", fmt.Errorf("tipo desconocido: %s (usar tv, movie, special u ova)", animeType)
+		}
+		values.Add("type[]", value)
+	}
+
+	for _, status := range f.Statuses {
+		value, exists := browseStatuses[normalizeBrowseValue(status)]
+		if !exists {
+			return "", fmt.Errorf("estado desconocido: %s (usar emision, finalizado o proximamente)", status)
+		}
+		values.Add("status[]", value)
+	}
+
+	if f.Order != "" {
+		value, exists := browseOrders[normalizeBrowseValue(f.Order)]
+		if !exists {
+			return "", fmt.Errorf("orden desconocido: %s (usar default, updated, added, title o rating)", f.Order)
+		}
+		values.Set("order", value)
+	}
+
+	if f.Query != "" {
+		values.Set("q", f.Query)
+	}
+
+	// AnimeFLV espera los corchetes sin escapar (genre[]=accion)
+	query := strings.NewReplacer("%5B", "[", "%5D", "]").Replace(values.Encode())
+
+	return fmt.Sprintf("%s/browse?%s", urlBase, query), nil
+}
+
+// normalizeBrowseValue pasa a minúsculas, quita tildes y cambia espacios por guiones
+func normalizeBrowseValue(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	value = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n").Replace(value)
+	return strings.Join(strings.Fields(value), "-")
+}
+
+// genreSlugByName busca el género por su nombre mostrado (ej. "Ciencia Ficción")
+func genreSlugByName(name string) string {
+	normalized := normalizeBrowseValue(name)
+	for slug, genreName := range animeGenres {
+		if normalizeBrowseValue(genreName) == normalized {
+			return slug
+		}
+	}
+
+	return ""
+}
+
+// printGenres muestra los géneros disponibles para --genre
+func printGenres() {
+	slugs := make([]string, 0, len(animeGenres))
+	for slug := range animeGenres {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	fmt.Println("Géneros disponibles:")
+	for _, slug := range slugs {
+		fmt.Printf("   • %-22s %s\n", slug, animeGenres[slug])
+	}
+}
+
+// browseAnime lista los animes que cumplen los filtros.
+// Recorre la paginación hasta maxPages páginas (0 = todas).
+func browseAnime(filter BrowseFilter, maxPages int) ([]Anime, error) {
+	pageURL, err := filter.URL()
+	if err != nil {
+		return nil, permanent(err)
+	}
+
+	var animesList []Anime
+	seen := make(map[string]bool)
+	// Páginas ya descargadas: un enlace "siguiente" que vuelve atrás no debe repetir el recorrido
+	visitedPages := make(map[string]bool)
+
+	for page := 1; pageURL != ""; page++ {
+		if maxPages > 0 && page > maxPages {
+			fmt.Fprintf(os.Stderr, "⚠️  Se alcanzó el límite de %d páginas de resultados (--max-pages)\n", maxPages)
+			break
+		}
+
+		if visitedPages[pageURL] {
+			break
+		}
+		visitedPages[pageURL] = true
+
+		var pageAnimes []Anime
+		var nextPage string
+
+		// Una página sin resultados ni paginación puede ser un listado vacío o una página
+		// incompleta (ej. sin renderizar): se prueba la siguiente estrategia por las dudas
+		err := pageFetcher.fetch(pageURL, func(doc *goquery.Document) bool {
+			pageAnimes = selectors.searchResults(doc)
+			nextPage = selectors.nextSearchPage(doc)
+			return len(pageAnimes) > 0 || nextPage != ""
+		})
+		// Ninguna estrategia encontró resultados: el listado está vacío
+		if errors.Is(err, errNoContent) {
+			err = nil
+		}
+		if err != nil {
+			// Los resultados de páginas anteriores siguen siendo válidos
+			if len(animesList) > 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  Error obteniendo la página %d de resultados: %v\n", page, err)
+				break
+			}
+			return nil, err
+		}
+
+		for _, anime := range pageAnimes {
+			if !seen[anime.Link] {
+				seen[anime.Link] = true
+				animesList = append(animesList, anime)
+			}
+		}
+
+		pageURL = ""
+		if nextPage != "" {
+			pageURL = absoluteURL(nextPage)
+		}
+	}
+
+	return animesList, nil
+}
+
+// listFlag flag de texto que acepta valores separados por comas y puede repetirse
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// parseYears convierte los años indicados en --year
+func parseYears(values []string) ([]int, error) {
+	var years []int
+	for _, value := range values {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("año inválido: %s", value)
+		}
+		years = append(years, year)
+	}
+
+	return years, nil
+}
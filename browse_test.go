@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fixtureFetcher sirve páginas desde testdata y cuenta cuántas veces se pidió cada una
+type fixtureFetcher struct {
+	pages    map[string]string // URL -> fixture
+	requests map[string]int
+}
+
+func (f *fixtureFetcher) Name() string {
+	return "fixture"
+}
+
+func (f *fixtureFetcher) Fetch(pageURL string) (string, error) {
+	f.requests[pageURL]++
+
+	fixture, exists := f.pages[pageURL]
+	if !exists {
+		return "", fmt.Errorf("página inesperada: %s", pageURL)
+	}
+
+	return fixture, nil
+}
+
+func TestBrowseAnimeStopsOnRepeatedPage(t *testing.T) {
+	page1 := urlBase + "/browse?q=naruto"
+	page2 := urlBase + "/browse?q=naruto&page=2"
+
+	fetcher := &fixtureFetcher{
+		pages: map[string]string{
+			page1: loadFixture(t, "browse", "page1.html"),
+			page2: loadFixture(t, "browse", "page2.html"),
+		},
+		requests: make(map[string]int),
+	}
+
+	previous := pageFetcher
+	pageFetcher = &fetchChain{fetchers: []Fetcher{fetcher}}
+	t.Cleanup(func() { pageFetcher = previous })
+
+	animes, err := browseAnime(BrowseFilter{Query: "naruto"}, 0)
+	if err != nil {
+		t.Fatalf("browseAnime: %v", err)
+	}
+
+	want := []string{"/anime/naruto", "/anime/naruto-shippuden-hd", "/anime/boruto-naruto-next-generations"}
+	if len(animes) != len(want) {
+		t.Fatalf("%d animes; se esperaban %d: %+v", len(animes), len(want), animes)
+	}
+	for i, link := range want {
+		if animes[i].Link != link {
+			t.Errorf("anime %d = %q; se esperaba %q", i, animes[i].Link, link)
+		}
+	}
+
+	for _, pageURL := range []string{page1, page2} {
+		if fetcher.requests[pageURL] != 1 {
+			t.Errorf("%s pedida %d veces; se esperaba 1", pageURL, fetcher.requests[pageURL])
+		}
+	}
+}
+
+func TestBrowseFilterURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  BrowseFilter
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "solo texto",
+			filter: BrowseFilter{Query: "naruto shippuden"},
+			want:   "/browse?q=naruto+shippuden",
+		},
+		{
+			// Slug, nombre con tilde y mayúsculas, y nombre de varias palabras
+			name:   "géneros por slug o nombre",
+			filter: BrowseFilter{Genres: []string{"accion", "Fantasía", "ciencia ficción", "Aventuras"}},
+			want:   "/browse?genre[]=accion&genre[]=fantasia&genre[]=ciencia-ficcion&genre[]=aventura",
+		},
+		{
+			name: "alias de tipo, estado y orden",
+			filter: BrowseFilter{
+				Types:    []string{"Película", "anime"},
+				Statuses: []string{"En Emisión", "finished"},
+				Order:    "Calificación",
+			},
+			want: "/browse?order=rating&status[]=1&status[]=2&type[]=movie&type[]=tv",
+		},
+		{
+			name:   "todos los filtros",
+			filter: BrowseFilter{Query: "isekai", Genres: []string{"magia"}, Years: []int{2023, 2024}, Types: []string{"ova"}, Statuses: []string{"proximamente"}, Order: "added"},
+			want:   "/browse?genre[]=magia&order=added&q=isekai&status[]=3&type[]=ova&year[]=2023&year[]=2024",
+		},
+		{
+			name:    "género desconocido",
+			filter:  BrowseFilter{Genres: []string{"cyberpunk"}},
+			wantErr: "género desconocido",
+		},
+		{
+			name:    "año inválido",
+			filter:  BrowseFilter{Years: []int{98}},
+			wantErr: "año inválido",
+		},
+		{
+			name:    "tipo desconocido",
+			filter:  BrowseFilter{Types: []string{"serie"}},
+			wantErr: "tipo desconocido",
+		},
+		{
+			name:    "estado desconocido",
+			filter:  BrowseFilter{Statuses: []string{"cancelado"}},
+			wantErr: "estado desconocido",
+		},
+		{
+			name:    "orden desconocido",
+			filter:  BrowseFilter{Order: "popular"},
+			wantErr: "orden desconocido",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.URL()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v; se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if got != urlBase+tt.want {
+				t.Errorf("URL = %q\nse esperaba %q", got, urlBase+tt.want)
+			}
+		})
+	}
+}
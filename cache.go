@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// defaultCacheTTL tiempo que una página guardada se considera vigente
+const defaultCacheTTL = 6 * time.Hour
+
+// pageCache caché en disco del HTML de las páginas de AnimeFLV
+var pageCache = &htmlCache{}
+
+// htmlCache guarda el HTML de cada URL en un archivo; la fecha de modificación marca su antigüedad
+type htmlCache struct {
+	dir     string
+	ttl     time.Duration
+	refresh bool // no leer la caché pero sí actualizarla
+
+	hits    atomic.Int64
+	fetched atomic.Int64
+}
+
+// defaultCacheDir directorio de caché del usuario (~/.cache/animeflv-downloader en Linux)
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "animeflv-downloader")
+	}
+
+	return filepath.Join(dir, "animeflv-downloader")
+}
+
+// newHTMLCache crea la caché; dir vacío la desactiva
+func newHTMLCache(dir string, ttl time.Duration, refresh bool) *htmlCache {
+	return &htmlCache{dir: dir, ttl: ttl, refresh: refresh}
+}
+
+// enabled indica si la caché está activa
+func (c *htmlCache) enabled() bool {
+	return c != nil && c.dir != "" && c.ttl > 0
+}
+
+// path devuelve el archivo donde se guarda la URL
+func (c *htmlCache) path(pageURL string) string {
+	sum := sha256.Sum256([]byte(pageURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".html")
+}
+
+// Get devuelve el HTML guardado si existe y no expiró
+func (c *htmlCache) Get(pageURL string) (string, bool) {
+	if !c.enabled() || c.refresh {
+		return "", false
+	}
+
+	file := c.path(pageURL)
+	info, err := os.Stat(file)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return "", false
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+
+	c.hits.Add(1)
+	return string(data), true
+}
+
+// Put guarda el HTML descargado de la URL
+func (c *htmlCache) Put(pageURL, html string) {
+	if !c.enabled() {
+		return
+	}
+
+	c.fetched.Add(1)
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  No se pudo crear el directorio de caché: %v\n", err)
+		return
+	}
+
+	// Escritura atómica para no dejar páginas a medias si se corta el programa
+	file := c.path(pageURL)
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(html), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  No se pudo guardar la página en caché: %v\n", err)
+		return
+	}
+	os.Rename(tmpFile, file)
+}
+
+// Delete descarta la página guardada (ej. si no tenía el contenido esperado)
+func (c *htmlCache) Delete(pageURL string) {
+	if !c.enabled() {
+		return
+	}
+
+	os.Remove(c.path(pageURL))
+}
+
+// cached devuelve la página desde la caché o la obtiene con fetch y la guarda
+func (c *htmlCache) cached(pageURL string, fetch func() (string, error)) (string, error) {
+	if html, ok := c.Get(pageURL); ok {
+		return html, nil
+	}
+
+	html, err := fetch()
+	if err != nil {
+		return "", err
+	}
+
+	// Un desafío anti-bot no es la página: guardarlo lo serviría hasta que venza la caché
+	if !isChallengeHTML(html) {
+		c.Put(pageURL, html)
+	}
+	return html, nil
+}
+
+// printStats muestra las estadísticas de uso de la caché al final de la ejecución
+func (c *htmlCache) printStats() {
+	if !c.enabled() {
+		return
+	}
+
+	hits, fetched := c.hits.Load(), c.fetched.Load()
+	if hits+fetched == 0 {
+		return
+	}
+
+	fmt.Printf("\n💾 Caché de páginas (%s):\n", c.dir)
+	fmt.Printf("   • Desde caché: %d\n", hits)
+	fmt.Printf("   • Descargadas: %d\n", fetched)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cdpnetwork "github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// challengeTimeout tiempo máximo que se espera a que Chrome resuelva un desafío
+const challengeTimeout = 30 * time.Second
+
+// errChallenge indica que la página respondió con un desafío anti-bot sin resolver
+var errChallenge = errors.New("desafío anti-bot detectado (Cloudflare)")
+
+// challengeMarkers textos exclusivos de la página intermedia de desafío de Cloudflare.
+// Las páginas normales también cargan /cdn-cgi/challenge-platform/, por eso no sirve como señal.
+var challengeMarkers = []string{
+	"window._cf_chl_opt",
+	"<title>just a moment...</title>",
+}
+
+// session estado de la sesión obtenida al resolver desafíos con Chrome
+var session struct {
+	mu        sync.Mutex
+	userAgent string
+	solvedAt  map[string]time.Time
+}
+
+// solveMutex evita que varias peticiones abran Chrome a la vez para el mismo desafío
+var solveMutex sync.Mutex
+
+// isChallengeHTML indica si el HTML corresponde a una página de desafío
+func isChallengeHTML(html string) bool {
+	page := strings.ToLower(html)
+	for _, marker := range challengeMarkers {
+		if strings.Contains(page, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isChallengeHeader indica si Cloudflare marcó la respuesta como desafío (cf-mitigated: challenge)
+func isChallengeHeader(header http.Header) bool {
+	return strings.EqualFold(header.Get("cf-mitigated"), "challenge")
+}
+
+// isChallengeResponse indica si la respuesta HTTP es un desafío anti-bot.
+// Lee como máximo 64KB del cuerpo; el llamador debe cerrarlo igualmente.
+func isChallengeResponse(resp *http.Response) bool {
+	if isChallengeHeader(resp.Header) {
+		return true
+	}
+
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return isChallengeHTML(string(body))
+}
+
+// sessionUserAgent devuelve el user agent de Chrome si se resolvió un desafío
+func sessionUserAgent() string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	return session.userAgent
+}
+
+// setSessionUserAgent fija el user agent asociado a las cookies de la sesión
+func setSessionUserAgent(userAgent string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.userAgent = userAgent
+}
+
+// waitChallenge espera a que Chrome supere el desafío; marca challenged si lo llegó a ver.
+// La sesión la exporta quien llama, una sola vez.
+func waitChallenge(challenged *bool) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		deadline := time.Now().Add(challengeTimeout)
+
+		for {
+			var html string
+			if err := chromedp.OuterHTML("html", &html).Do(ctx); err != nil {
+				return err
+			}
+
+			if !isChallengeHTML(html) {
+				return nil
+			}
+
+			*challenged = true
+			if time.Now().After(deadline) {
+				return nil
+			}
+
+			if err := chromedp.Sleep(1 * time.Second).Do(ctx); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// exportChromeSession copia las cookies (cf_clearance, etc.) y el user agent de Chrome al cliente HTTP
+func exportChromeSession(ctx context.Context, pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return permanent(err)
+	}
+
+	chromeCookies, err := cdpnetwork.GetCookies().WithUrls([]string{pageURL}).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("error obteniendo cookies de Chrome: %v", err)
+	}
+
+	var cookies []*http.Cookie
+	for _, c := range chromeCookies {
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		if !c.Session && c.Expires > 0 {
+			cookie.Expires = time.Unix(int64(c.Expires), 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	sessionJar.SetCookies(u, cookies)
+
+	// Cloudflare asocia cf_clearance al user agent que resolvió el desafío
+	var userAgent string
+	if err := chromedp.Evaluate(`navigator.userAgent`, &userAgent).Do(ctx); err != nil {
+		return fmt.Errorf("error obteniendo user agent de Chrome: %v", err)
+	}
+
+	session.mu.Lock()
+	session.userAgent = userAgent
+	if session.solvedAt == nil {
+		session.solvedAt = make(map[string]time.Time)
+	}
+	session.solvedAt[u.Hostname()] = time.Now()
+	session.mu.Unlock()
+
+	// Guardar también el user agent junto a las cookies
+	if err := sessionJar.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  No se pudieron guardar las cookies: %v\n", err)
+	}
+
+	return nil
+}
+
+// solveChallenge abre la página en Chrome para resolver el desafío una sola vez por host.
+// Solo se hace con AnimeFLV: abrir en Chrome una API o un proveedor (ej. un POST a MEGA) no sirve.
+func solveChallenge(pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return err
+	}
+
+	if !isAnimeFLVHost(u.Hostname()) {
+		return errChallenge
+	}
+
+	// Sin red no hay forma de resolver el desafío grabado
+	if recorder.replaying() {
+		return errChallenge
+	}
+
+	solveMutex.Lock()
+	defer solveMutex.Unlock()
+
+	// Otra petición pudo resolverlo mientras se esperaba el turno
+	session.mu.Lock()
+	solvedAt, solved := session.solvedAt[u.Hostname()]
+	session.mu.Unlock()
+	if solved && time.Since(solvedAt) < time.Minute {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "\n🛡️  Desafío anti-bot detectado, resolviendo con Chrome...")
+
+	// Se exportan las cookies aunque Chrome no haya visto el desafío: su sesión sí es válida
+	var html string
+	var challenged bool
+	err = runChrome(pageURL, challengeTimeout+15*time.Second,
+		waitChallenge(&challenged),
+		chromedp.OuterHTML("html", &html),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return exportChromeSession(ctx, pageURL)
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("error resolviendo desafío con Chrome: %v", err)
+	}
+
+	if isChallengeHTML(html) {
+		return errChallenge
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsChallengeHTML(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    bool
+	}{
+		// Las páginas normales detrás de Cloudflare cargan el script jsd de challenge-platform
+		{"normal.html", false},
+		{"interstitial.html", true},
+	}
+
+	for _, tt := range tests {
+		if got := isChallengeHTML(loadFixture(t, "challenge", tt.fixture)); got != tt.want {
+			t.Errorf("isChallengeHTML(%s) = %v; se esperaba %v", tt.fixture, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPFetcherChallenge(t *testing.T) {
+	normal := loadFixture(t, "challenge", "normal.html")
+
+	tests := []struct {
+		name    string
+		header  string // valor de cf-mitigated
+		body    string
+		wantErr error
+	}{
+		{name: "página normal con script jsd", body: normal},
+		{name: "desafío con código 200", body: loadFixture(t, "challenge", "interstitial.html"), wantErr: errChallenge},
+		{name: "cabecera cf-mitigated", header: "challenge", body: normal, wantErr: errChallenge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set("cf-mitigated", tt.header)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			fetcher := &httpFetcher{timeout: 5 * time.Second}
+			html, err := fetcher.Fetch(server.URL)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v; se esperaba %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if html != tt.body {
+				t.Errorf("HTML de %d bytes; se esperaban %d", len(html), len(tt.body))
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Estados posibles de un enlace tras la verificación
+const (
+	statusAlive   = "alive"
+	statusDead    = "dead"
+	statusUnknown = "unknown"
+)
+
+// megaAPIURL endpoint de la API de MEGA usado para consultar archivos
+const megaAPIURL = "https://g.api.mega.co.nz/cs"
+
+// megaFileRegex extrae el identificador de archivo de los formatos de enlace de MEGA
+var megaFileRegex = regexp.MustCompile(`mega(?:\.co)?\.nz/(?:file/|#!|embed/#!|embed/)([A-Za-z0-9_-]{8})`)
+
+// megaFolderRegex extrae el identificador de carpeta de los formatos de enlace de MEGA
+var megaFolderRegex = regexp.MustCompile(`mega(?:\.co)?\.nz/(?:folder/|#F!)([A-Za-z0-9_-]{8})`)
+
+// deadPageMarkers textos que indican que el archivo fue eliminado
+var deadPageMarkers = []string{
+	"file was removed",
+	"file has been removed",
+	"file not found",
+	"has been deleted",
+	"no longer available",
+	"video not found",
+	"archivo eliminado",
+	"archivo no encontrado",
+	"el archivo no existe",
+}
+
+// checkSummary cuenta los enlaces de un episodio por estado
+type checkSummary struct {
+	Alive   int
+	Dead    int
+	Unknown int
+}
+
+// checkLink verifica un enlace con la lógica del proveedor correspondiente
+func checkLink(link string) string {
+	if megaFileRegex.MatchString(link) || megaFolderRegex.MatchString(link) {
+		return checkMegaLink(link)
+	}
+
+	return checkHTTPLink(link)
+}
+
+// checkMegaLink consulta la API de MEGA para saber si el archivo o carpeta existe
+func checkMegaLink(link string) string {
+	apiURL := megaAPIURL + "?id=0"
+	var payload string
+
+	if matches := megaFileRegex.FindStringSubmatch(link); len(matches) > 1 {
+		payload = fmt.Sprintf(`[{"a":"g","p":%q}]`, matches[1])
+	} else if matches := megaFolderRegex.FindStringSubmatch(link); len(matches) > 1 {
+		apiURL += "&n=" + url.QueryEscape(matches[1])
+		payload = `[{"a":"f","c":1,"r":1,"ca":1}]`
+	} else {
+		return statusUnknown
+	}
+
+	req, err := newBrowserRequest("POST", apiURL, bytes.NewBufferString(payload))
+	if err != nil {
+		return statusUnknown
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doWithRetry(newHTTPClient(15*time.Second), req)
+	if err != nil {
+		return statusUnknown
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return statusUnknown
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return statusUnknown
+	}
+
+	// La API responde un arreglo con un objeto o con un código de error numérico
+	var results []json.RawMessage
+	if err := json.Unmarshal(body, &results); err != nil || len(results) == 0 {
+		return statusUnknown
+	}
+
+	var code int
+	if err := json.Unmarshal(results[0], &code); err != nil {
+		return statusAlive
+	}
+
+	switch code {
+	case -9, -16, -17:
+		// ENOENT, EBLOCKED y EOVERQUOTA del propietario
+		return statusDead
+	default:
+		// Otros códigos, como EARGS (-2: petición inválida, ej. id mal extraído), no dicen nada del archivo
+		return statusUnknown
+	}
+}
+
+// checkHTTPLink verifica un enlace por código de estado y marcadores de archivo eliminado
+func checkHTTPLink(link string) string {
+	req, err := newBrowserRequest("GET", link, nil)
+	if err != nil {
+		return statusUnknown
+	}
+
+	resp, err := doWithRetry(newHTTPClient(15*time.Second), req)
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == 404 || statusErr.StatusCode == 410) {
+		return statusDead
+	}
+	if err != nil {
+		return statusUnknown
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return statusUnknown
+	}
+
+	// MediaFire redirige a error.php cuando el archivo fue eliminado
+	if strings.Contains(resp.Request.URL.Path, "error.php") {
+		return statusDead
+	}
+
+	// Solo las páginas HTML pueden contener marcadores; un archivo binario está vivo
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return statusAlive
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	if err != nil {
+		return statusUnknown
+	}
+
+	page := strings.ToLower(string(body))
+	for _, marker := range deadPageMarkers {
+		if strings.Contains(page, marker) {
+			return statusDead
+		}
+	}
+
+	return statusAlive
+}
+
+// checkExport verifica todos los enlaces de la exportación y muestra un resumen por episodio
+func checkExport(export *ExportFile) checkSummary {
+	var total checkSummary
+
+	for i := range export.Episodes {
+		episode := &export.Episodes[i]
+		var summary checkSummary
+
+		fmt.Printf("Verificando %s", episode.Name)
+
+		for j := range episode.Downloads {
+			download := &episode.Downloads[j]
+			download.Status = checkLink(download.DownloadURL)
+
+			switch download.Status {
+			case statusAlive:
+				summary.Alive++
+			case statusDead:
+				summary.Dead++
+			default:
+				summary.Unknown++
+			}
+		}
+
+		fmt.Printf(" ✅ %d vivos, ❌ %d muertos, ❔ %d desconocidos\n", summary.Alive, summary.Dead, summary.Unknown)
+
+		total.Alive += summary.Alive
+		total.Dead += summary.Dead
+		total.Unknown += summary.Unknown
+	}
+
+	return total
+}
+
+// runCheck implementa el comando `check`: verifica los enlaces de archivos generados
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Uso: ./programa check archivo.txt|archivo.json|archivo.metalink ...")
+		fmt.Println("El estado se anota en el mismo archivo; para un metalink se escribe una copia archivo.metalink.check.txt")
+		fs.PrintDefaults()
+	}
+	registerNetworkFlags(fs)
+	fs.Parse(args)
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no se indicó archivo a verificar")
+	}
+
+	for _, filename := range fs.Args() {
+		export, err := loadExport(filename)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n🔎 Verificando enlaces de %s\n\n", filename)
+		total := checkExport(&export)
+
+		// Los metalinks no tienen dónde guardar el estado; se anota una copia en texto
+		output := filename
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".json":
+			err = writeJSONExport(output, export)
+		case ".metalink", ".meta4":
+			output = filename + ".check.txt"
+			err = writeTextExport(output, export)
+		default:
+			err = writeTextExport(output, export)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n📊 Resumen: %d vivos, %d muertos, %d desconocidos\n", total.Alive, total.Dead, total.Unknown)
+		if output != filename {
+			fmt.Printf("📁 Resultado anotado en una copia (el metalink no se modifica): %s\n", output)
+		} else {
+			fmt.Printf("📁 Resultado anotado en: %s\n", output)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/fetch"
+	cdpnetwork "github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// chromeRetryPolicy usa menos intentos que HTTP: abrir Chrome es lento y suele haber otra estrategia en la cadena
+var chromeRetryPolicy = retryPolicy{
+	MaxAttempts: 2,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    10 * time.Second,
+}
+
+// chromeProfileMutex Chrome bloquea el perfil: solo una instancia puede usarlo a la vez
+var chromeProfileMutex sync.Mutex
+
+// runChrome abre Chrome headless, navega a la página y ejecuta las acciones indicadas
+func runChrome(pageURL string, timeout time.Duration, actions ...chromedp.Action) error {
+	// La navegación comparte el límite de peticiones con HTTP
+	rateLimiter.WaitURL(pageURL)
+
+	// Configurar chromedp con opciones más robustas
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", true),
+		chromedp.Flag("disable-javascript", false), // Habilitamos JS ya que puede ser necesario
+		chromedp.Flag("disable-web-security", true),
+		chromedp.Flag("disable-features", "VizDisplayCompositor"),
+		chromedp.Flag("disable-background-timer-throttling", true),
+		chromedp.Flag("disable-backgrounding-occluded-windows", true),
+		chromedp.Flag("disable-renderer-backgrounding", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("disable-extensions", true),
+		chromedp.Flag("disable-plugins", true),
+		chromedp.Flag("disable-images", true),
+		chromedp.Flag("disable-default-apps", true),
+	)
+
+	// Pasar a Chrome el mismo proxy que usa el cliente HTTP
+	proxyURL, err := activeProxy(pageURL)
+	if err != nil {
+		return permanent(err)
+	}
+	if isSocksWithAuth(proxyURL) {
+		return permanent(fmt.Errorf("Chrome no admite proxies SOCKS5 con usuario y clave (usa un proxy HTTP o --fetch http)"))
+	}
+	opts = append(opts, chromeProxyOptions(proxyURL)...)
+
+	// Con perfil persistente Chrome conserva sus cookies y preferencias entre ejecuciones
+	if network.ChromeProfile != "" {
+		chromeProfileMutex.Lock()
+		defer chromeProfileMutex.Unlock()
+
+		opts = append(opts, chromedp.UserDataDir(network.ChromeProfile))
+	}
+
+	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
+	defer cancel()
+
+	// Configurar contexto sin logging para evitar errores de cookies
+	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(func(string, ...interface{}) {}))
+	defer cancel()
+
+	ctx, cancel = context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var all []chromedp.Action
+	if proxyURL != nil && proxyURL.User != nil {
+		listenProxyAuth(ctx, proxyURL.User)
+		all = append(all, fetch.Enable().WithHandleAuthRequests(true))
+	}
+
+	// Reutilizar en Chrome las cookies guardadas (ej. cf_clearance de una ejecución anterior)
+	if cookies := sessionJar.chromeCookies(pageURL); len(cookies) > 0 {
+		all = append(all, cdpnetwork.SetCookies(cookies))
+	}
+
+	all = append(all, chromedp.Navigate(pageURL))
+	all = append(all, actions...)
+
+	return chromedp.Run(ctx, all...)
+}
+
+// chromeProxyOptions convierte el proxy configurado en flags de Chrome
+func chromeProxyOptions(proxyURL *url.URL) []chromedp.ExecAllocatorOption {
+	if proxyURL == nil {
+		return nil
+	}
+
+	// Chrome no acepta credenciales en proxy-server; se responden con listenProxyAuth
+	server := *proxyURL
+	server.User = nil
+	if server.Scheme == "socks5h" {
+		server.Scheme = "socks5"
+	}
+
+	opts := []chromedp.ExecAllocatorOption{chromedp.ProxyServer(server.String())}
+
+	if noProxy := getEnvAny("NO_PROXY", "no_proxy"); noProxy != "" {
+		opts = append(opts, chromedp.Flag("proxy-bypass-list", strings.ReplaceAll(noProxy, ",", ";")))
+	}
+
+	return opts
+}
+
+// listenProxyAuth responde los desafíos de autenticación del proxy con las credenciales configuradas
+func listenProxyAuth(ctx context.Context, user *url.Userinfo) {
+	password, _ := user.Password()
+
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *fetch.EventRequestPaused:
+			go chromedp.Run(ctx, fetch.ContinueRequest(ev.RequestID))
+		case *fetch.EventAuthRequired:
+			go chromedp.Run(ctx, fetch.ContinueWithAuth(ev.RequestID, &fetch.AuthChallengeResponse{
+				Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+				Username: user.Username(),
+				Password: password,
+			}))
+		}
+	})
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	cdpnetwork "github.com/chromedp/cdproto/network"
+)
+
+// sessionJar cookies compartidas por todos los clientes HTTP (incluye las exportadas desde Chrome)
+var sessionJar = newPersistentJar()
+
+// netscapeHeader cabecera estándar de los archivos cookies.txt
+const netscapeHeader = "# Netscape HTTP Cookie File"
+
+// userAgentComment comentario con el que se guarda el user agent asociado a las cookies
+const userAgentComment = "# User-Agent: "
+
+// storedCookie cookie tal como se guarda en formato Netscape
+type storedCookie struct {
+	Domain   string
+	HostOnly bool
+	Path     string
+	Secure   bool
+	HttpOnly bool
+	Expires  time.Time // cero = cookie de sesión
+	Name     string
+	Value    string
+}
+
+// persistentJar envuelve cookiejar y guarda una copia de las cookies para persistirlas en disco
+type persistentJar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	file    string
+	cookies map[string]storedCookie
+}
+
+// newPersistentJar crea un jar vacío; sin archivo asociado funciona solo en memoria
+func newPersistentJar() *persistentJar {
+	jar, _ := cookiejar.New(nil)
+
+	return &persistentJar{
+		jar:     jar,
+		cookies: make(map[string]storedCookie),
+	}
+}
+
+// cookieKey identifica una cookie por dominio, ruta y nombre
+func cookieKey(c storedCookie) string {
+	return c.Domain + "|" + c.Path + "|" + c.Name
+}
+
+// Cookies devuelve las cookies a enviar a la URL
+func (j *persistentJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies guarda las cookies recibidas y, si cambiaron, actualiza el archivo
+func (j *persistentJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	changed := false
+	for _, cookie := range cookies {
+		stored, ok := newStoredCookie(u, cookie)
+		if !ok {
+			continue
+		}
+
+		key := cookieKey(stored)
+		previous, exists := j.cookies[key]
+
+		// MaxAge < 0 o fecha pasada: el servidor está borrando la cookie
+		if cookie.MaxAge < 0 || (!stored.Expires.IsZero() && stored.Expires.Before(time.Now())) {
+			if exists {
+				delete(j.cookies, key)
+				changed = true
+			}
+			continue
+		}
+
+		if !exists || previous != stored {
+			j.cookies[key] = stored
+			changed = true
+		}
+	}
+	j.mu.Unlock()
+
+	if changed {
+		if err := j.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  No se pudieron guardar las cookies: %v\n", err)
+		}
+	}
+}
+
+// newStoredCookie normaliza una cookie recibida de u; descarta las de otros dominios
+func newStoredCookie(u *url.URL, cookie *http.Cookie) (storedCookie, bool) {
+	host := strings.ToLower(u.Hostname())
+
+	stored := storedCookie{
+		Domain:   strings.ToLower(strings.TrimPrefix(cookie.Domain, ".")),
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+	}
+
+	if stored.Domain == "" {
+		stored.Domain = host
+		stored.HostOnly = true
+	} else if host != stored.Domain && !strings.HasSuffix(host, "."+stored.Domain) {
+		return stored, false
+	}
+
+	// Ruta por defecto según RFC 6265: el directorio de la URL
+	if !strings.HasPrefix(stored.Path, "/") {
+		stored.Path = "/"
+		if dir := path.Dir(u.Path); strings.HasPrefix(dir, "/") {
+			stored.Path = dir
+		}
+	}
+
+	switch {
+	case cookie.MaxAge > 0:
+		stored.Expires = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).Truncate(time.Second)
+	case !cookie.Expires.IsZero():
+		stored.Expires = cookie.Expires.Truncate(time.Second)
+	}
+
+	return stored, true
+}
+
+// Load importa un archivo cookies.txt (formato Netscape) y lo usa para guardar los cambios.
+// Si el archivo no existe se creará al recibir la primera cookie.
+func (j *persistentJar) Load(file string) error {
+	j.mu.Lock()
+	j.file = file
+	j.mu.Unlock()
+
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error abriendo archivo de cookies: %v", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.HasPrefix(line, userAgentComment) {
+			setSessionUserAgent(strings.TrimSpace(strings.TrimPrefix(line, userAgentComment)))
+			continue
+		}
+
+		// Los navegadores exportan las cookies HttpOnly con este prefijo
+		httpOnly := strings.HasPrefix(line, "#HttpOnly_")
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return fmt.Errorf("línea %d inválida en %s: se esperaban 7 campos separados por tabulador", lineNumber, file)
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("línea %d inválida en %s: expiración %q", lineNumber, file, fields[4])
+		}
+
+		stored := storedCookie{
+			Domain:   strings.ToLower(strings.TrimPrefix(fields[0], ".")),
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+			Name:     fields[5],
+			Value:    fields[6],
+		}
+		if expires > 0 {
+			stored.Expires = time.Unix(expires, 0)
+			if stored.Expires.Before(now) {
+				continue
+			}
+		}
+
+		j.load(stored)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error leyendo archivo de cookies: %v", err)
+	}
+
+	return nil
+}
+
+// load agrega una cookie leída del archivo sin volver a guardarlo
+func (j *persistentJar) load(stored storedCookie) {
+	scheme := "http"
+	if stored.Secure {
+		scheme = "https"
+	}
+
+	cookie := &http.Cookie{
+		Name:     stored.Name,
+		Value:    stored.Value,
+		Path:     stored.Path,
+		Secure:   stored.Secure,
+		HttpOnly: stored.HttpOnly,
+		Expires:  stored.Expires,
+	}
+	if !stored.HostOnly {
+		cookie.Domain = stored.Domain
+	}
+
+	j.jar.SetCookies(&url.URL{Scheme: scheme, Host: stored.Domain, Path: stored.Path}, []*http.Cookie{cookie})
+
+	j.mu.Lock()
+	j.cookies[cookieKey(stored)] = stored
+	j.mu.Unlock()
+}
+
+// Save escribe las cookies vigentes en el archivo asociado (formato Netscape)
+func (j *persistentJar) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == "" {
+		return nil
+	}
+
+	var cookies []storedCookie
+	now := time.Now()
+	for _, cookie := range j.cookies {
+		if cookie.Expires.IsZero() || cookie.Expires.After(now) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	sort.Slice(cookies, func(a, b int) bool {
+		return cookieKey(cookies[a]) < cookieKey(cookies[b])
+	})
+
+	var builder strings.Builder
+	builder.WriteString(netscapeHeader + "\n")
+	builder.WriteString("# Generado por animeflv-downloader. Se puede usar con curl -b o importar en el navegador.\n")
+	if userAgent := sessionUserAgent(); userAgent != "" {
+		builder.WriteString(userAgentComment + userAgent + "\n")
+	}
+	builder.WriteString("\n")
+
+	for _, cookie := range cookies {
+		domain, includeSubdomains := cookie.Domain, "FALSE"
+		if !cookie.HostOnly {
+			domain, includeSubdomains = "."+cookie.Domain, "TRUE"
+		}
+		if cookie.HttpOnly {
+			domain = "#HttpOnly_" + domain
+		}
+
+		secure := "FALSE"
+		if cookie.Secure {
+			secure = "TRUE"
+		}
+
+		var expires int64
+		if !cookie.Expires.IsZero() {
+			expires = cookie.Expires.Unix()
+		}
+
+		fmt.Fprintf(&builder, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, includeSubdomains, cookie.Path, secure, expires, cookie.Name, cookie.Value)
+	}
+
+	if dir := filepath.Dir(j.file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creando directorio de cookies: %v", err)
+		}
+	}
+
+	// Escritura atómica: las cookies no deben quedar a medias si se corta el programa
+	tmpFile := j.file + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(builder.String()), 0600); err != nil {
+		return fmt.Errorf("error escribiendo archivo de cookies: %v", err)
+	}
+
+	return os.Rename(tmpFile, j.file)
+}
+
+// chromeCookies devuelve las cookies guardadas para el host de la URL en formato de Chrome
+func (j *persistentJar) chromeCookies(pageURL string) []*cdpnetwork.CookieParam {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var params []*cdpnetwork.CookieParam
+	for _, cookie := range j.cookies {
+		if host != cookie.Domain && (cookie.HostOnly || !strings.HasSuffix(host, "."+cookie.Domain)) {
+			continue
+		}
+
+		param := &cdpnetwork.CookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HttpOnly,
+		}
+		if cookie.HostOnly {
+			param.URL = u.Scheme + "://" + cookie.Domain + cookie.Path
+		} else {
+			param.Domain = "." + cookie.Domain
+		}
+		if !cookie.Expires.IsZero() {
+			expires := cdp.TimeSinceEpoch(cookie.Expires)
+			param.Expires = &expires
+		}
+		params = append(params, param)
+	}
+
+	return params
+}
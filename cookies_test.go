@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersistentJarRoundTrip(t *testing.T) {
+	previousUserAgent := sessionUserAgent()
+	setSessionUserAgent("Mozilla/5.0 (X11; Linux x86_64) Chrome/130.0")
+	t.Cleanup(func() { setSessionUserAgent(previousUserAgent) })
+
+	file := filepath.Join(t.TempDir(), "cookies.txt")
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	jar := newPersistentJar()
+	if err := jar.Load(file); err != nil {
+		t.Fatalf("Load sin archivo: %v", err)
+	}
+
+	u, _ := url.Parse("https://www3.animeflv.net/anime/naruto")
+	jar.SetCookies(u, []*http.Cookie{
+		// Cookie de dominio, HttpOnly y con vencimiento (como cf_clearance)
+		{Name: "cf_clearance", Value: "abc.123", Domain: ".animeflv.net", Path: "/", Secure: true, HttpOnly: true, Expires: expires},
+		// Cookie de sesión solo para el host
+		{Name: "PHPSESSID", Value: "s3ss10n", Path: "/"},
+	})
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("no se guardó el archivo: %v", err)
+	}
+
+	wantLines := []string{
+		netscapeHeader,
+		userAgentComment + "Mozilla/5.0 (X11; Linux x86_64) Chrome/130.0",
+		"#HttpOnly_.animeflv.net\tTRUE\t/\tTRUE\t" + strconv.FormatInt(expires.Unix(), 10) + "\tcf_clearance\tabc.123",
+		"www3.animeflv.net\tFALSE\t/\tFALSE\t0\tPHPSESSID\ts3ss10n",
+	}
+	for _, line := range wantLines {
+		if !strings.Contains(string(content), line+"\n") {
+			t.Errorf("falta la línea %q en:\n%s", line, content)
+		}
+	}
+
+	setSessionUserAgent("")
+	loaded := newPersistentJar()
+	if err := loaded.Load(file); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if len(loaded.cookies) != len(jar.cookies) {
+		t.Fatalf("%d cookies leídas; se esperaban %d", len(loaded.cookies), len(jar.cookies))
+	}
+	for key, want := range jar.cookies {
+		got, exists := loaded.cookies[key]
+		if !exists {
+			t.Errorf("falta la cookie %s", key)
+			continue
+		}
+		if got.Domain != want.Domain || got.HostOnly != want.HostOnly || got.Path != want.Path ||
+			got.Secure != want.Secure || got.HttpOnly != want.HttpOnly || got.Value != want.Value ||
+			!got.Expires.Equal(want.Expires) {
+			t.Errorf("cookie %s = %+v; se esperaba %+v", key, got, want)
+		}
+	}
+
+	if userAgent := sessionUserAgent(); userAgent != "Mozilla/5.0 (X11; Linux x86_64) Chrome/130.0" {
+		t.Errorf("user agent = %q tras leer el archivo", userAgent)
+	}
+
+	// La marca de dominio decide si la cookie se envía a otros subdominios
+	other, _ := url.Parse("https://cdn.animeflv.net/")
+	names := map[string]bool{}
+	for _, cookie := range loaded.Cookies(other) {
+		names[cookie.Name] = true
+	}
+	if !names["cf_clearance"] || names["PHPSESSID"] {
+		t.Errorf("cookies enviadas a otro subdominio: %v; se esperaba solo cf_clearance", names)
+	}
+}
+
+func TestPersistentJarLoadBrowserExport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cookies.txt")
+	content := strings.Join([]string{
+		netscapeHeader,
+		"# exportado desde el navegador",
+		"",
+		"#HttpOnly_.animeflv.net\tTRUE\t/\tTRUE\t4102444800\tcf_clearance\txyz",
+		".animeflv.net\tTRUE\t/\tFALSE\t946684800\tvencida\tvieja", // año 2000: se descarta
+		"www3.animeflv.net\tFALSE\t/\tFALSE\t0\tsesion\t1",
+		"",
+	}, "\n")
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	jar := newPersistentJar()
+	if err := jar.Load(file); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	u, _ := url.Parse("https://www3.animeflv.net/")
+	names := map[string]bool{}
+	for _, cookie := range jar.Cookies(u) {
+		names[cookie.Name] = true
+	}
+	if len(names) != 2 || !names["cf_clearance"] || !names["sesion"] {
+		t.Errorf("cookies = %v; se esperaban cf_clearance y sesion", names)
+	}
+
+	if stored := jar.cookies["animeflv.net|/|cf_clearance"]; !stored.HttpOnly || stored.HostOnly {
+		t.Errorf("cf_clearance = %+v; se esperaba HttpOnly y de dominio", stored)
+	}
+
+	if err := os.WriteFile(file, []byte("www3.animeflv.net\tFALSE\t/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := newPersistentJar().Load(file); err == nil {
+		t.Error("se esperaba error con una línea sin los 7 campos")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit hasta cuándo se busca la próxima ejecución de una expresión cron
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronDescriptors atajos de expresiones cron habituales
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// schedule calcula cuándo toca la próxima ejecución
+type schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule ejecución cada cierto tiempo
+type intervalSchedule time.Duration
+
+// Next devuelve el momento de la próxima ejecución
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// cronSchedule expresión cron de 5 campos (minuto hora día mes día-de-la-semana), en hora local.
+// Cada campo es un conjunto de bits con los valores permitidos.
+type cronSchedule struct {
+	minute, hour, day, month, weekday uint64
+	// Como en cron, si día y día de la semana están restringidos basta con que coincida uno.
+	// Un campo que empieza con * (ej. */2) no cuenta como restringido.
+	dayAny, weekdayAny bool
+}
+
+// cronAllHours bits de un campo de hora que incluye las 24 horas
+const cronAllHours = 1<<24 - 1
+
+// parseCron interpreta una expresión cron: "*/30 * * * *", "0 8,20 * * 1-5" o @daily
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, exists := cronDescriptors[strings.ToLower(expr)]; exists {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expresión cron inválida %q: se esperan 5 campos (minuto hora día mes día-semana)", expr)
+	}
+
+	var c cronSchedule
+	var err error
+
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minuto: %v", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hora: %v", err)
+	}
+	if c.day, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("día: %v", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("mes: %v", err)
+	}
+	if c.weekday, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("día de la semana: %v", err)
+	}
+
+	// El 7 también es domingo
+	if c.weekday&(1<<7) != 0 {
+		c.weekday |= 1
+	}
+
+	c.dayAny = strings.HasPrefix(fields[2], "*")
+	c.weekdayAny = strings.HasPrefix(fields[4], "*")
+
+	return &c, nil
+}
+
+// parseCronField interpreta un campo: *, valores, rangos (1-5) y pasos (*/15, 0-30/10) separados por comas
+func parseCronField(field string, minValue, maxValue int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("paso inválido: %s", part)
+			}
+		}
+
+		from, to := minValue, maxValue
+		if rangeText != "*" {
+			fromText, toText, isRange := strings.Cut(rangeText, "-")
+
+			var err error
+			from, err = strconv.Atoi(fromText)
+			if err != nil {
+				return 0, fmt.Errorf("valor inválido: %s", part)
+			}
+
+			to = from
+			if isRange {
+				to, err = strconv.Atoi(toText)
+				if err != nil {
+					return 0, fmt.Errorf("valor inválido: %s", part)
+				}
+			} else if hasStep {
+				// "5/15" equivale a "5-máximo/15"
+				to = maxValue
+			}
+		}
+
+		if from < minValue || to > maxValue || from > to {
+			return 0, fmt.Errorf("fuera de rango (%d-%d): %s", minValue, maxValue, part)
+		}
+
+		for value := from; value <= to; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+// matchesDay indica si la fecha cumple los campos de día y día de la semana
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	day := c.day&(1<<t.Day()) != 0
+	weekday := c.weekday&(1<<t.Weekday()) != 0
+
+	if c.dayAny || c.weekdayAny {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// Next devuelve el primer minuto posterior a after que cumple la expresión (cero si no hay ninguno)
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<t.Month()) == 0:
+			t = cronAdvance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location()))
+		case !c.matchesDay(t):
+			t = cronAdvance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()))
+		case c.hour&(1<<t.Hour()) == 0:
+			// En tiempo absoluto: con time.Date una hora inexistente (se adelanta el reloj) vuelve atrás
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		case c.hour != cronAllHours && repeatedWallClock(t):
+			// Al terminar el horario de verano una hora se repite: como en cron,
+			// las expresiones con hora fija se ejecutan solo la primera vez
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// cronAdvance devuelve next si avanza; si time.Date lo normalizó hacia atrás (medianoche
+// inexistente por cambio de horario) avanza un minuto para no quedar en un bucle
+func cronAdvance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+
+	return t.Add(time.Minute)
+}
+
+// repeatedWallClock indica si la hora local ya se mostró una hora antes (hora repetida al atrasar el reloj)
+func repeatedWallClock(t time.Time) bool {
+	earlier := t.Add(-time.Hour)
+	return earlier.Day() == t.Day() && earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute()
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestCronNext(t *testing.T) {
+	utc := time.UTC
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "rango de horas y días hábiles",
+			expr:  "0 9-17 * * 1-5",
+			after: time.Date(2026, 10, 16, 18, 0, 0, 0, utc), // viernes
+			want:  time.Date(2026, 10, 19, 9, 0, 0, 0, utc),  // lunes
+		},
+		{
+			name:  "paso sobre todo el campo",
+			expr:  "*/15 * * * *",
+			after: time.Date(2026, 10, 18, 10, 7, 30, 0, utc),
+			want:  time.Date(2026, 10, 18, 10, 15, 0, 0, utc),
+		},
+		{
+			name:  "paso sobre un rango",
+			expr:  "0-30/10 * * * *",
+			after: time.Date(2026, 10, 18, 10, 31, 0, 0, utc),
+			want:  time.Date(2026, 10, 18, 11, 0, 0, 0, utc),
+		},
+		{
+			name:  "paso desde un valor",
+			expr:  "5/20 * * * *",
+			after: time.Date(2026, 10, 18, 10, 6, 0, 0, utc),
+			want:  time.Date(2026, 10, 18, 10, 25, 0, 0, utc),
+		},
+		{
+			name:  "lista de valores",
+			expr:  "0 8,20 * * *",
+			after: time.Date(2026, 10, 18, 8, 0, 0, 0, utc),
+			want:  time.Date(2026, 10, 18, 20, 0, 0, 0, utc),
+		},
+		{
+			name:  "7 es domingo",
+			expr:  "0 0 * * 7",
+			after: time.Date(2026, 10, 14, 12, 0, 0, 0, utc), // miércoles
+			want:  time.Date(2026, 10, 18, 0, 0, 0, 0, utc),
+		},
+		{
+			name:  "0 es domingo",
+			expr:  "0 0 * * 0",
+			after: time.Date(2026, 10, 14, 12, 0, 0, 0, utc),
+			want:  time.Date(2026, 10, 18, 0, 0, 0, 0, utc),
+		},
+		{
+			name:  "día del mes o día de la semana: coincide el día",
+			expr:  "0 0 13 * 5",
+			after: time.Date(2026, 10, 10, 0, 0, 0, 0, utc), // sábado
+			want:  time.Date(2026, 10, 13, 0, 0, 0, 0, utc), // martes 13
+		},
+		{
+			name:  "día del mes o día de la semana: coincide el viernes",
+			expr:  "0 0 13 * 5",
+			after: time.Date(2026, 10, 13, 0, 0, 0, 0, utc),
+			want:  time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+		},
+		{
+			name:  "día con * y paso no se une al día de la semana",
+			expr:  "0 0 */2 * 1",
+			after: time.Date(2026, 10, 13, 0, 0, 0, 0, utc),
+			want:  time.Date(2026, 10, 19, 0, 0, 0, 0, utc), // lunes 19 (día impar)
+		},
+		{
+			name:  "cambio de mes y año",
+			expr:  "@monthly",
+			after: time.Date(2026, 12, 15, 0, 0, 0, 0, utc),
+			want:  time.Date(2027, 1, 1, 0, 0, 0, 0, utc),
+		},
+		{
+			name:  "29 de febrero",
+			expr:  "0 0 29 2 *",
+			after: time.Date(2026, 10, 18, 0, 0, 0, 0, utc),
+			want:  time.Date(2028, 2, 29, 0, 0, 0, 0, utc),
+		},
+	}
+
+	for _, tt := range tests {
+		cron, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: parseCron(%q): %v", tt.name, tt.expr, err)
+		}
+
+		if got := cron.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%s) = %s; se esperaba %s", tt.name, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextDST(t *testing.T) {
+	// En 2026 Nueva York adelanta el reloj el 8 de marzo (2:00 → 3:00) y lo atrasa el 1 de noviembre (2:00 → 1:00)
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	// En Santiago el 6 de septiembre de 2026 empieza a la 1:00: la medianoche no existe
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	firstOneThirty := time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(location) // 1:30 EDT
+	secondOne := time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC).In(location)       // 1:00 EST
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "la hora que no existe se salta",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, 3, 8, 0, 0, 0, 0, location),
+			want:  time.Date(2026, 3, 9, 2, 30, 0, 0, location),
+		},
+		{
+			name:  "cada hora al adelantar el reloj",
+			expr:  "0 * * * *",
+			after: time.Date(2026, 3, 8, 1, 30, 0, 0, location),
+			want:  time.Date(2026, 3, 8, 3, 0, 0, 0, location),
+		},
+		{
+			name:  "hora fija en la hora repetida se ejecuta una vez",
+			expr:  "30 1 * * *",
+			after: firstOneThirty,
+			want:  time.Date(2026, 11, 2, 1, 30, 0, 0, location),
+		},
+		{
+			name:  "cada media hora sigue durante la hora repetida",
+			expr:  "*/30 * * * *",
+			after: firstOneThirty,
+			want:  secondOne,
+		},
+		{
+			name:  "día que empieza sin medianoche",
+			expr:  "0 12 6 9 *",
+			after: time.Date(2026, 9, 5, 12, 0, 0, 0, santiago),
+			want:  time.Date(2026, 9, 6, 12, 0, 0, 0, santiago),
+		},
+	}
+
+	for _, tt := range tests {
+		cron, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: parseCron(%q): %v", tt.name, tt.expr, err)
+		}
+
+		if got := cron.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%s) = %s; se esperaba %s", tt.name, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q): se esperaba error", expr)
+		}
+	}
+}
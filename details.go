@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// errAnimeNotFound la página del anime no existe o no tiene ficha (ej. un slug mal escrito)
+var errAnimeNotFound = errors.New("no se encontró la ficha del anime")
+
+// animeInfoRegex datos del anime en el script de la página: [id, título, slug, próximo episodio]
+var animeInfoRegex = regexp.MustCompile(`var\s+anime_info\s*=\s*(\[[^\]]*\])`)
+
+// episodesScriptRegex lista de episodios en el script de la página: [[número, id], ...]
+var episodesScriptRegex = regexp.MustCompile(`var\s+episodes\s*=\s*(\[\s*(?:\[[^\]]*\]\s*,?\s*)*\])`)
+
+// AnimeDetails ficha de un anime obtenida de su página
+type AnimeDetails struct {
+	ID            int            `json:"id,omitempty"` // id interno de AnimeFLV, crece con cada alta
+	Title         string         `json:"title"`
+	Link          string         `json:"link"`
+	Type          string         `json:"type,omitempty"`
+	Cover         string         `json:"cover,omitempty"`
+	Synopsis      string         `json:"synopsis,omitempty"`
+	Genres        []string       `json:"genres,omitempty"`
+	Status        string         `json:"status,omitempty"` // En emisión, Finalizado, Próximamente
+	AltTitles     []string       `json:"alt_titles,omitempty"`
+	Score         float64        `json:"score,omitempty"`
+	NextEpisode   string         `json:"next_episode,omitempty"` // fecha AAAA-MM-DD, solo en emisión
+	LatestEpisode int            `json:"latest_episode,omitempty"`
+	Related       []RelatedAnime `json:"related,omitempty"`
+}
+
+// RelatedAnime entrada relacionada con un anime (precuela, secuela, etc.)
+type RelatedAnime struct {
+	Name     string `json:"name"`
+	Link     string `json:"link"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// animeDetails extrae la ficha del anime de su página
+func (p *selectorProfile) animeDetails(doc *goquery.Document, animeLink string) *AnimeDetails {
+	page := doc.Selection
+
+	details := &AnimeDetails{
+		Title:    selectionText(page, p.Details.Title),
+		Link:     animeLink,
+		Type:     normalizeAnimeType(selectionText(page, p.Details.Type)),
+		Cover:    selectionImage(page, p.Details.Cover),
+		Synopsis: selectionText(page, p.Details.Synopsis),
+		Status:   normalizeAnimeStatus(selectionText(page, p.Details.Status)),
+		Score:    parseRating(selectionText(page, p.Details.Score)),
+	}
+
+	details.Genres = selectionTexts(page, p.Details.Genres)
+	for _, title := range selectionTexts(page, p.Details.AltTitles) {
+		if title != details.Title {
+			details.AltTitles = append(details.AltTitles, title)
+		}
+	}
+
+	if p.Details.RelatedItem != "" {
+		page.Find(p.Details.RelatedItem).Each(func(i int, s *goquery.Selection) {
+			link := s.Find(p.Details.RelatedLink).First()
+			href, exists := link.Attr("href")
+			name := strings.TrimSpace(link.Text())
+			if !exists || name == "" {
+				return
+			}
+
+			// La relación aparece entre paréntesis después del enlace: "Naruto Shippuden (Secuela)"
+			relation := strings.TrimSpace(strings.Replace(s.Text(), link.Text(), "", 1))
+			relation = strings.Trim(relation, "() ")
+
+			details.Related = append(details.Related, RelatedAnime{Name: name, Link: href, Relation: relation})
+		})
+	}
+
+	// La fecha del próximo episodio y la lista completa de episodios solo aparecen en los scripts
+	doc.Find("script").Each(func(i int, s *goquery.Selection) {
+		script := s.Text()
+
+		if match := animeInfoRegex.FindStringSubmatch(script); match != nil {
+			var info []string
+			if err := json.Unmarshal([]byte(match[1]), &info); err == nil {
+				if len(info) >= 1 {
+					details.ID, _ = strconv.Atoi(info[0])
+				}
+				if len(info) >= 4 {
+					details.NextEpisode = strings.TrimSpace(info[3])
+				}
+			}
+		}
+
+		if match := episodesScriptRegex.FindStringSubmatch(script); match != nil {
+			var episodes [][]float64
+			if err := json.Unmarshal([]byte(match[1]), &episodes); err == nil {
+				for _, episode := range episodes {
+					if len(episode) > 0 {
+						details.LatestEpisode = max(details.LatestEpisode, int(episode[0]))
+					}
+				}
+			}
+		}
+	})
+
+	// Sin script (ej. perfil antiguo) se usa la lista de episodios renderizada
+	if details.LatestEpisode == 0 {
+		for _, episode := range p.episodes(doc) {
+			details.LatestEpisode = max(details.LatestEpisode, parseEpisodeNumber(episode.Name))
+		}
+	}
+
+	return details
+}
+
+// selectionTexts devuelve el texto de cada elemento que coincide, sin vacíos
+func selectionTexts(s *goquery.Selection, selector string) []string {
+	if selector == "" {
+		return nil
+	}
+
+	var texts []string
+	s.Find(selector).Each(func(i int, item *goquery.Selection) {
+		if text := strings.Join(strings.Fields(item.Text()), " "); text != "" {
+			texts = append(texts, text)
+		}
+	})
+
+	return texts
+}
+
+// normalizeAnimeStatus corrige la tilde que AnimeFLV omite en "En emision"
+func normalizeAnimeStatus(status string) string {
+	if strings.EqualFold(status, "En emision") {
+		return "En emisión"
+	}
+
+	return status
+}
+
+// animeLinkFromArg acepta un slug (naruto), una ruta (/anime/naruto) o una URL completa
+func animeLinkFromArg(arg string) (string, error) {
+	arg = strings.TrimSpace(arg)
+
+	if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		u, err := url.Parse(arg)
+		if err != nil || !strings.HasPrefix(u.Path, "/anime/") {
+			return "", fmt.Errorf("URL de anime inválida: %s", arg)
+		}
+		return u.Path, nil
+	}
+
+	if strings.HasPrefix(arg, "/anime/") {
+		return arg, nil
+	}
+
+	if arg == "" || strings.Contains(arg, "/") {
+		return "", fmt.Errorf("slug de anime inválido: %s", arg)
+	}
+
+	return "/anime/" + arg, nil
+}
+
+// getAnimeDetails obtiene solo la ficha del anime (no necesita la lista de episodios)
+func getAnimeDetails(animeLink string) (*AnimeDetails, error) {
+	var details *AnimeDetails
+
+	err := pageFetcher.fetch(urlBase+animeLink, func(doc *goquery.Document) bool {
+		details = selectors.animeDetails(doc, animeLink)
+		return details.Title != ""
+	})
+	if err != nil {
+		var statusErr *httpStatusError
+		if errors.Is(err, errNoContent) || (errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound) {
+			return nil, permanent(fmt.Errorf("%w en %s", errAnimeNotFound, animeLink))
+		}
+		return nil, err
+	}
+
+	if details == nil || details.Title == "" {
+		return nil, permanent(fmt.Errorf("%w en %s", errAnimeNotFound, animeLink))
+	}
+
+	return details, nil
+}
+
+// printAnimeDetails muestra la ficha del anime
+func printAnimeDetails(details *AnimeDetails) {
+	fmt.Printf("📺 %s\n", details.Title)
+	if len(details.AltTitles) > 0 {
+		fmt.Printf("   Otros títulos: %s\n", strings.Join(details.AltTitles, " / "))
+	}
+	fmt.Printf("   Enlace: %s\n", urlBase+details.Link)
+	if details.Type != "" {
+		fmt.Printf("   Tipo: %s\n", details.Type)
+	}
+	if details.Status != "" {
+		fmt.Printf("   Estado: %s\n", details.Status)
+	}
+	if details.LatestEpisode > 0 {
+		fmt.Printf("   Último episodio: %d\n", details.LatestEpisode)
+	}
+	if details.NextEpisode != "" {
+		fmt.Printf("   Próximo episodio: %s\n", details.NextEpisode)
+	}
+	if details.Score > 0 {
+		fmt.Printf("   Puntuación: ⭐ %.1f\n", details.Score)
+	}
+	if len(details.Genres) > 0 {
+		fmt.Printf("   Géneros: %s\n", strings.Join(details.Genres, ", "))
+	}
+	if details.Synopsis != "" {
+		fmt.Printf("\n%s\n", details.Synopsis)
+	}
+
+	if len(details.Related) > 0 {
+		fmt.Println("\n🔗 Relacionados:")
+		for _, related := range details.Related {
+			if related.Relation != "" {
+				fmt.Printf("   • %s (%s): %s\n", related.Name, related.Relation, related.Link)
+			} else {
+				fmt.Printf("   • %s: %s\n", related.Name, related.Link)
+			}
+		}
+	}
+}
+
+// runInfo implementa el comando `info`: muestra la ficha de un anime por slug o URL
+func runInfo(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Mostrar la ficha en formato JSON")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	fs.Parse(args)
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+	selectors = profile
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("uso: info [opciones] slug|/anime/slug|URL")
+	}
+
+	animeLink, err := animeLinkFromArg(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	details, err := getAnimeDetails(animeLink)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		content, err := json.MarshalIndent(details, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error generando JSON: %v", err)
+		}
+		fmt.Println(string(content))
+	} else {
+		printAnimeDetails(details)
+		pageCache.printStats()
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// downloadResolved descarga un enlace directo en el directorio indicado
+func downloadResolved(resolved *Resolved, dir, fallbackName string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creando directorio: %v", err)
+	}
+
+	name := resolved.FileName
+	if name == "" {
+		name = fallbackName
+	}
+	destination := filepath.Join(dir, sanitizeFilename(name))
+
+	// Los playlists HLS se unen como un único archivo TS
+	if isHLS(resolved.URL) {
+		destination = strings.TrimSuffix(destination, filepath.Ext(destination)) + ".ts"
+		partial := destination + ".part"
+
+		if err := downloadHLS(resolved, partial); err != nil {
+			os.Remove(partial)
+			return "", err
+		}
+
+		if err := os.Rename(partial, destination); err != nil {
+			return "", fmt.Errorf("error renombrando archivo: %v", err)
+		}
+
+		return destination, nil
+	}
+
+	req, err := newBrowserRequest("GET", resolved.URL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for key, value := range resolved.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// Sin timeout global: los archivos de video pueden tardar varios minutos
+	resp, err := doWithRetry(newHTTPClient(0), withoutRateLimit(req))
+	if err != nil {
+		return "", fmt.Errorf("error haciendo request HTTP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("error HTTP: código de estado %d", resp.StatusCode)
+	}
+
+	// Escribir primero a un archivo temporal para no dejar descargas a medias
+	partial := destination + ".part"
+	file, err := os.Create(partial)
+	if err != nil {
+		return "", fmt.Errorf("error creando archivo: %v", err)
+	}
+
+	written, err := io.Copy(file, resp.Body)
+	file.Close()
+	if err != nil {
+		os.Remove(partial)
+		return "", fmt.Errorf("error descargando archivo: %v", err)
+	}
+
+	if resp.ContentLength > 0 && written != resp.ContentLength {
+		os.Remove(partial)
+		return "", fmt.Errorf("descarga incompleta: %d de %d bytes", written, resp.ContentLength)
+	}
+
+	if err := os.Rename(partial, destination); err != nil {
+		return "", fmt.Errorf("error renombrando archivo: %v", err)
+	}
+
+	return destination, nil
+}
+
+// downloadEpisodes descarga cada episodio usando el primer enlace directo que funcione.
+// Con verbose en false no se muestra el progreso (ej. procesos en paralelo).
+func downloadEpisodes(animeName string, episodes []Episode, allDownloads map[string][]Download, dir string, verbose bool) {
+	if verbose {
+		fmt.Printf("\n⬇️  Descargando episodios en %s\n", dir)
+	}
+
+	for i, episode := range episodes {
+		if verbose {
+			fmt.Printf("Descargando episodio %d/%d: %s", i+1, len(episodes), episodeLabel(episode))
+		}
+
+		// En una franquicia el nombre del episodio se repite entre temporadas
+		episodeAnime := animeName
+		if episode.Anime != "" {
+			episodeAnime = episode.Anime
+		}
+		fallbackName := fmt.Sprintf("%s_%s.mp4", episodeAnime, episode.Name)
+		downloaded := false
+
+		for _, download := range allDownloads[episode.Link] {
+			if download.Direct == nil {
+				continue
+			}
+
+			path, err := downloadResolved(download.Direct, dir, fallbackName)
+			if err != nil {
+				if verbose {
+					fmt.Printf("\n   ⚠️  %s falló: %v", download.ProviderName, err)
+				}
+				continue
+			}
+
+			if verbose {
+				fmt.Printf(" ✅ %s\n", path)
+			}
+			downloaded = true
+			break
+		}
+
+		if !downloaded && verbose {
+			fmt.Printf(" ❌ Sin enlaces directos disponibles\n")
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFile representa el contenido de un archivo de enlaces exportado
+type ExportFile struct {
+	Anime     string          `json:"anime"`
+	Generated string          `json:"generated"`
+	Details   *AnimeDetails   `json:"details,omitempty"`
+	Franchise []*AnimeDetails `json:"franchise,omitempty"` // entradas en orden de emisión (--franchise)
+	Episodes  []ExportEpisode `json:"episodes"`
+}
+
+// ExportEpisode representa un episodio con sus enlaces dentro de una exportación
+type ExportEpisode struct {
+	Episode
+	Downloads []Download `json:"downloads"`
+}
+
+// linkStatusLabels traduce el estado de un enlace para el archivo de texto
+var linkStatusLabels = map[string]string{
+	statusAlive:   "vivo",
+	statusDead:    "muerto",
+	statusUnknown: "desconocido",
+}
+
+// newExportFile arma la exportación a partir de los episodios y enlaces obtenidos
+func newExportFile(animeName string, episodes []Episode, allDownloads map[string][]Download) ExportFile {
+	export := ExportFile{
+		Anime:     animeName,
+		Generated: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	for _, episode := range episodes {
+		downloads, exists := allDownloads[episode.Link]
+		if !exists || len(downloads) == 0 {
+			continue
+		}
+
+		export.Episodes = append(export.Episodes, ExportEpisode{
+			Episode:   episode,
+			Downloads: downloads,
+		})
+	}
+
+	return export
+}
+
+// writeExportFiles escribe la exportación en texto, JSON y metalink (si hay enlaces directos o MEGA).
+// baseName es la ruta sin extensión; devuelve la ruta del archivo de texto.
+func writeExportFiles(baseName string, export ExportFile) (string, error) {
+	filename := baseName + ".txt"
+
+	if err := writeTextExport(filename, export); err != nil {
+		return "", err
+	}
+
+	if err := writeJSONExport(baseName+".json", export); err != nil {
+		return "", err
+	}
+
+	// Generar metalink con enlaces directos y MEGA
+	episodes, allDownloads := export.downloads()
+	mg, err := CreateMetalinkFromDownloads(export.Anime, filepath.Base(baseName), episodes, allDownloads)
+	if err == nil {
+		mg.SaveToFile(filename + ".metalink")
+	}
+
+	return filename, nil
+}
+
+// downloads separa la exportación en episodios y enlaces por episodio
+func (export ExportFile) downloads() ([]Episode, map[string][]Download) {
+	episodes := make([]Episode, len(export.Episodes))
+	allDownloads := make(map[string][]Download, len(export.Episodes))
+
+	for i, episode := range export.Episodes {
+		episodes[i] = episode.Episode
+		allDownloads[episode.Link] = episode.Downloads
+	}
+
+	return episodes, allDownloads
+}
+
+// writeTextExport escribe la exportación en el formato de texto
+func writeTextExport(filename string, export ExportFile) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creando archivo: %v", err)
+	}
+	defer file.Close()
+
+	// Escribir encabezado
+	fmt.Fprintf(file, "ENLACES DE DESCARGA - %s\n", export.Anime)
+	fmt.Fprintf(file, "Generado el: %s\n", export.Generated)
+	writeTextDetails(file, export.Details)
+	fmt.Fprintf(file, "========================================\n\n")
+
+	// Escribir enlaces por episodio, con una sección por cada entrada de la franquicia
+	currentAnime := ""
+	for _, episode := range export.Episodes {
+		if episode.Anime != "" && episode.Anime != currentAnime {
+			currentAnime = episode.Anime
+			fmt.Fprintf(file, "TEMPORADA: %s\n", currentAnime)
+			fmt.Fprintf(file, "========================================\n\n")
+		}
+
+		fmt.Fprintf(file, "EPISODIO: %s\n", episode.Name)
+		fmt.Fprintf(file, "----------------------------------------\n")
+
+		for _, download := range episode.Downloads {
+			fmt.Fprintf(file, "Proveedor: %s\n", download.ProviderName)
+			fmt.Fprintf(file, "Enlace: %s\n", download.DownloadURL)
+			if download.Direct != nil {
+				// Un playlist HLS no se descarga con un gestor de descargas, se marca aparte
+				if isHLS(download.Direct.URL) {
+					fmt.Fprintf(file, "HLS: %s\n", download.Direct.URL)
+				} else {
+					fmt.Fprintf(file, "Directo: %s\n", download.Direct.URL)
+				}
+			}
+			if download.Status != "" {
+				fmt.Fprintf(file, "Estado: %s\n", linkStatusLabels[download.Status])
+			}
+			fmt.Fprintf(file, "\n")
+		}
+
+		fmt.Fprintf(file, "\n")
+	}
+
+	return nil
+}
+
+// writeTextDetails escribe en el encabezado los datos principales de la ficha del anime
+func writeTextDetails(w io.Writer, details *AnimeDetails) {
+	if details == nil {
+		return
+	}
+
+	if details.Type != "" {
+		fmt.Fprintf(w, "Tipo: %s\n", details.Type)
+	}
+	if details.Status != "" {
+		fmt.Fprintf(w, "Emisión: %s\n", details.Status)
+	}
+	if details.NextEpisode != "" {
+		fmt.Fprintf(w, "Próximo episodio: %s\n", details.NextEpisode)
+	}
+	if details.Score > 0 {
+		fmt.Fprintf(w, "Puntuación: %.1f\n", details.Score)
+	}
+	if len(details.Genres) > 0 {
+		fmt.Fprintf(w, "Géneros: %s\n", strings.Join(details.Genres, ", "))
+	}
+}
+
+// writeJSONExport escribe la exportación en formato JSON
+func writeJSONExport(filename string, export ExportFile) error {
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+
+	return os.WriteFile(filename, append(content, '\n'), 0644)
+}
+
+// loadExport lee una exportación en formato texto, JSON o metalink
+func loadExport(filename string) (ExportFile, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return ExportFile{}, fmt.Errorf("error leyendo archivo: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		var export ExportFile
+		if err := json.Unmarshal(content, &export); err != nil {
+			return ExportFile{}, fmt.Errorf("error parseando JSON: %v", err)
+		}
+		return export, nil
+	case ".metalink", ".meta4":
+		export, err := parseMetalinkExport(content)
+		if err == nil && export.Anime == "" {
+			export.Anime = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		}
+		return export, err
+	default:
+		return parseTextExport(string(content)), nil
+	}
+}
+
+// parseTextExport reconstruye la exportación desde el formato de texto
+func parseTextExport(text string) ExportFile {
+	var export ExportFile
+	var current *ExportEpisode
+	var download *Download
+	var anime string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		if after, ok := strings.CutPrefix(line, "ENLACES DE DESCARGA - "); ok {
+			export.Anime = after
+		} else if after, ok := strings.CutPrefix(line, "Generado el:"); ok {
+			export.Generated = strings.TrimSpace(after)
+		} else if current == nil && anime == "" && parseTextDetail(&export, line) {
+			continue
+		} else if after, ok := strings.CutPrefix(line, "TEMPORADA:"); ok {
+			anime = strings.TrimSpace(after)
+		} else if after, ok := strings.CutPrefix(line, "EPISODIO:"); ok {
+			name := strings.TrimSpace(after)
+			// El texto no guarda el enlace del episodio, se usa el nombre como clave
+			// (con el anime delante en las franquicias, donde los nombres se repiten)
+			link := name
+			if anime != "" {
+				link = anime + " - " + name
+			}
+			export.Episodes = append(export.Episodes, ExportEpisode{Episode: Episode{Name: name, Link: link, Anime: anime}})
+			current = &export.Episodes[len(export.Episodes)-1]
+			download = nil
+		} else if after, ok := strings.CutPrefix(line, "Proveedor:"); ok && current != nil {
+			current.Downloads = append(current.Downloads, Download{ProviderName: strings.TrimSpace(after)})
+			download = &current.Downloads[len(current.Downloads)-1]
+		} else if after, ok := strings.CutPrefix(line, "Enlace:"); ok && download != nil {
+			download.DownloadURL = strings.TrimSpace(after)
+		} else if after, ok := strings.CutPrefix(line, "Directo:"); ok && download != nil {
+			download.Direct = &Resolved{URL: strings.TrimSpace(after)}
+		} else if after, ok := strings.CutPrefix(line, "HLS:"); ok && download != nil {
+			download.Direct = &Resolved{URL: strings.TrimSpace(after)}
+		} else if after, ok := strings.CutPrefix(line, "Estado:"); ok && download != nil {
+			label := strings.TrimSpace(after)
+			for status, text := range linkStatusLabels {
+				if text == label {
+					download.Status = status
+				}
+			}
+		}
+	}
+
+	return export
+}
+
+// parseTextDetail lee una línea de datos del anime del encabezado (la inversa de writeTextDetails)
+func parseTextDetail(export *ExportFile, line string) bool {
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		return false
+	}
+	value = strings.TrimSpace(value)
+
+	details := export.Details
+	if details == nil {
+		details = &AnimeDetails{Title: export.Anime}
+	}
+
+	switch key {
+	case "Tipo":
+		details.Type = value
+	case "Emisión":
+		details.Status = value
+	case "Próximo episodio":
+		details.NextEpisode = value
+	case "Puntuación":
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		details.Score = score
+	case "Géneros":
+		details.Genres = nil
+		for _, genre := range strings.Split(value, ",") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				details.Genres = append(details.Genres, genre)
+			}
+		}
+	default:
+		return false
+	}
+
+	export.Details = details
+	return true
+}
+
+// parseMetalinkExport reconstruye la exportación desde un metalink (un archivo por episodio)
+func parseMetalinkExport(content []byte) (ExportFile, error) {
+	var metalink Metalink
+	if err := xml.Unmarshal(content, &metalink); err != nil {
+		return ExportFile{}, fmt.Errorf("error parseando metalink: %v", err)
+	}
+
+	var export ExportFile
+	export.Generated = metalink.Published
+
+	for _, file := range metalink.Files {
+		episode := ExportEpisode{Episode: Episode{Name: file.Name, Link: file.Name}}
+
+		for _, link := range file.URLs {
+			provider := link.Value
+			if u, err := url.Parse(link.Value); err == nil {
+				provider = u.Hostname()
+			}
+
+			episode.Downloads = append(episode.Downloads, Download{
+				ProviderName: provider,
+				DownloadURL:  strings.TrimSpace(link.Value),
+			})
+		}
+
+		export.Episodes = append(export.Episodes, episode)
+	}
+
+	return export, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTextExportRoundTrip(t *testing.T) {
+	export := ExportFile{
+		Anime:     "Dr. Stone",
+		Generated: "2026-10-18 12:00:00",
+		Details: &AnimeDetails{
+			Title:       "Dr. Stone",
+			Type:        "Anime",
+			Status:      "En emisión",
+			NextEpisode: "2026-10-25",
+			Score:       4.7,
+			Genres:      []string{"Aventuras", "Ciencia Ficción", "Shounen"},
+		},
+		Episodes: []ExportEpisode{
+			{
+				Episode: Episode{Name: "Episodio 1", Anime: "Dr. Stone"},
+				Downloads: []Download{
+					{
+						ProviderName: "MEGA",
+						DownloadURL:  "https://mega.nz/file/abc#key",
+						Status:       statusAlive,
+					},
+					{
+						ProviderName: "Stape",
+						DownloadURL:  "https://streamtape.com/v/xyz",
+						Direct:       &Resolved{URL: "https://streamtape.com/get_video?id=xyz"},
+						Status:       statusDead,
+					},
+				},
+			},
+			{
+				Episode: Episode{Name: "Episodio 1", Anime: "Dr. Stone: Stone Wars"},
+				Downloads: []Download{
+					{
+						ProviderName: "SW",
+						DownloadURL:  "https://streamwish.to/e/abc",
+						Direct:       &Resolved{URL: "https://cdn.example.com/hls/master.m3u8"},
+					},
+				},
+			},
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "export.txt")
+	if err := writeTextExport(filename, export); err != nil {
+		t.Fatalf("writeTextExport: %v", err)
+	}
+
+	loaded, err := loadExport(filename)
+	if err != nil {
+		t.Fatalf("loadExport: %v", err)
+	}
+
+	if loaded.Anime != export.Anime || loaded.Generated != export.Generated {
+		t.Errorf("encabezado = %q, %q; se esperaba %q, %q", loaded.Anime, loaded.Generated, export.Anime, export.Generated)
+	}
+
+	if !reflect.DeepEqual(loaded.Details, export.Details) {
+		t.Errorf("Details = %+v; se esperaba %+v", loaded.Details, export.Details)
+	}
+
+	if len(loaded.Episodes) != len(export.Episodes) {
+		t.Fatalf("%d episodios; se esperaban %d", len(loaded.Episodes), len(export.Episodes))
+	}
+
+	for i, episode := range export.Episodes {
+		got := loaded.Episodes[i]
+		if got.Name != episode.Name || got.Anime != episode.Anime {
+			t.Errorf("episodio %d = %q (%q); se esperaba %q (%q)", i, got.Name, got.Anime, episode.Name, episode.Anime)
+		}
+		if !reflect.DeepEqual(got.Downloads, episode.Downloads) {
+			t.Errorf("episodio %d: enlaces = %+v; se esperaba %+v", i, got.Downloads, episode.Downloads)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultFetchStrategy orden por defecto: HTTP es rápido y Chrome cubre las páginas que dependen de JS
+const defaultFetchStrategy = "http,chrome"
+
+// errNoContent se obtuvo la página pero ninguna estrategia encontró el contenido esperado
+var errNoContent = errors.New("la página no tiene el contenido esperado")
+
+// Fetcher obtiene el HTML de una página
+type Fetcher interface {
+	Name() string
+	Fetch(pageURL string) (string, error)
+}
+
+// pageFetcher cadena de estrategias usada para las páginas de AnimeFLV
+var pageFetcher, _ = newFetchChain(defaultFetchStrategy)
+
+// httpFetcher descarga la página con el cliente HTTP compartido (con reintentos)
+type httpFetcher struct {
+	timeout time.Duration
+}
+
+func (f *httpFetcher) Name() string {
+	return "http"
+}
+
+func (f *httpFetcher) Fetch(pageURL string) (string, error) {
+	req, err := newBrowserRequest("GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := doWithRetry(newHTTPClient(f.timeout), req)
+	if err != nil {
+		return "", fmt.Errorf("error haciendo request HTTP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("error HTTP: código de estado %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error leyendo respuesta: %w", err)
+	}
+
+	// Algunos desafíos llegan con código 200: no devolverlos como si fueran la página
+	if isChallengeHeader(resp.Header) || isChallengeHTML(string(body)) {
+		return "", errChallenge
+	}
+
+	return string(body), nil
+}
+
+// chromeFetcher carga la página en Chrome headless y devuelve el HTML renderizado.
+// Si aparece un desafío anti-bot espera a que se resuelva y exporta la sesión al cliente HTTP.
+type chromeFetcher struct {
+	wait      time.Duration
+	animeWait time.Duration // la página del anime tarda más en completar la lista de episodios
+	timeout   time.Duration
+}
+
+// waitFor devuelve la espera tras cargar la página
+func (f *chromeFetcher) waitFor(pageURL string) time.Duration {
+	if strings.HasPrefix(pageURL, urlBase+"/anime/") {
+		return f.animeWait
+	}
+
+	return f.wait
+}
+
+func (f *chromeFetcher) Name() string {
+	return "chrome"
+}
+
+func (f *chromeFetcher) Fetch(pageURL string) (string, error) {
+	var htmlContent string
+
+	err := withRetry(chromeRetryPolicy, func() error {
+		var challenged bool
+		return runChrome(pageURL, f.timeout+challengeTimeout,
+			chromedp.Sleep(f.waitFor(pageURL)), // Esperar carga
+			waitChallenge(&challenged),
+			chromedp.OuterHTML("html", &htmlContent),
+			// Si Chrome pasó un desafío, su sesión (cf_clearance) sirve también al cliente HTTP
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				if !challenged {
+					return nil
+				}
+				return exportChromeSession(ctx, pageURL)
+			}),
+		)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if isChallengeHTML(htmlContent) {
+		return "", errChallenge
+	}
+
+	if recorder.recording() {
+		err := recorder.Save(recordedResponse{
+			Method:  "GET",
+			URL:     pageURL,
+			Status:  http.StatusOK,
+			Headers: http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Body:    htmlContent,
+			Source:  "chrome",
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}
+
+	return htmlContent, nil
+}
+
+// cachedFetcher usa la caché de páginas antes de recurrir al fetcher envuelto
+type cachedFetcher struct {
+	cache   *htmlCache
+	fetcher Fetcher
+}
+
+func (f *cachedFetcher) Name() string {
+	return f.fetcher.Name()
+}
+
+func (f *cachedFetcher) Fetch(pageURL string) (string, error) {
+	return f.cache.cached(pageURL, func() (string, error) {
+		return f.fetcher.Fetch(pageURL)
+	})
+}
+
+// Invalidate descarta la página guardada (ej. si no tenía el contenido esperado)
+func (f *cachedFetcher) Invalidate(pageURL string) {
+	f.cache.Delete(pageURL)
+}
+
+// replayFetcher sirve las páginas grabadas con --record, vengan de Chrome o de HTTP
+type replayFetcher struct {
+	recorder *pageRecorder
+}
+
+func (f *replayFetcher) Name() string {
+	return "replay"
+}
+
+func (f *replayFetcher) Fetch(pageURL string) (string, error) {
+	recorded, err := f.recorder.Load("GET", pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	return recorded.Body, nil
+}
+
+// fetchChain prueba las estrategias en orden hasta obtener una página con el contenido esperado
+type fetchChain struct {
+	fetchers []Fetcher
+}
+
+// newFetchChain crea la cadena a partir de la lista de estrategias (ej. "http,chrome").
+// En modo replay se ignora la lista y todo sale de las grabaciones.
+func newFetchChain(strategy string) (*fetchChain, error) {
+	if recorder.replaying() {
+		return &fetchChain{fetchers: []Fetcher{&replayFetcher{recorder: recorder}}}, nil
+	}
+
+	chain := &fetchChain{}
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(strategy, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var fetcher Fetcher
+		switch name {
+		case "http":
+			fetcher = &httpFetcher{timeout: 15 * time.Second}
+		case "chrome":
+			fetcher = &chromeFetcher{wait: 2 * time.Second, animeWait: 3 * time.Second, timeout: 25 * time.Second}
+		default:
+			return nil, fmt.Errorf("estrategia de descarga desconocida: %s (usar http o chrome)", name)
+		}
+
+		chain.fetchers = append(chain.fetchers, &cachedFetcher{cache: pageCache, fetcher: fetcher})
+	}
+
+	if len(chain.fetchers) == 0 {
+		return nil, fmt.Errorf("--fetch debe incluir al menos una estrategia (http, chrome)")
+	}
+
+	return chain, nil
+}
+
+// fetch obtiene la página y la pasa a parse, que indica si encontró lo que buscaba.
+// Si no lo encontró se prueba la siguiente estrategia; al final parse queda aplicado
+// a la última página obtenida. Si se obtuvo alguna página pero ninguna tenía el contenido
+// esperado devuelve errNoContent (los llamadores que aceptan páginas vacías lo ignoran).
+func (c *fetchChain) fetch(pageURL string, parse func(doc *goquery.Document) bool) error {
+	var lastErr error
+	fetched := false
+
+	for _, fetcher := range c.fetchers {
+		htmlContent, err := fetcher.Fetch(pageURL)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", fetcher.Name(), err)
+			continue
+		}
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+		if err != nil {
+			lastErr = permanent(fmt.Errorf("error parseando HTML: %v", err))
+			continue
+		}
+
+		fetched = true
+		if parse(doc) {
+			return nil
+		}
+
+		// La página guardada no sirve: que la siguiente estrategia la vuelva a descargar
+		if cached, ok := fetcher.(*cachedFetcher); ok {
+			cached.Invalidate(pageURL)
+		}
+	}
+
+	if fetched {
+		return fmt.Errorf("%w: %s", errNoContent, pageURL)
+	}
+
+	return lastErr
+}
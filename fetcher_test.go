@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestFetchChain(t *testing.T) {
+	pageURL := urlBase + "/anime/naruto"
+	empty := &fixtureFetcher{pages: map[string]string{pageURL: "<html><body></body></html>"}, requests: map[string]int{}}
+	full := &fixtureFetcher{pages: map[string]string{pageURL: `<html><body><ul class="ListCaps"><li>1</li></ul></body></html>`}, requests: map[string]int{}}
+	failing := &fixtureFetcher{pages: map[string]string{}, requests: map[string]int{}}
+
+	hasEpisodes := func(doc *goquery.Document) bool {
+		return doc.Find("ul.ListCaps li").Length() > 0
+	}
+
+	tests := []struct {
+		name     string
+		fetchers []Fetcher
+		wantErr  error
+	}{
+		{"la segunda estrategia tiene el contenido", []Fetcher{empty, full}, nil},
+		{"ninguna página tiene el contenido", []Fetcher{empty, empty}, errNoContent},
+		{"una falla y la otra no tiene el contenido", []Fetcher{failing, empty}, errNoContent},
+	}
+
+	for _, tt := range tests {
+		chain := &fetchChain{fetchers: tt.fetchers}
+		err := chain.fetch(pageURL, hasEpisodes)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v; se esperaba %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	chain := &fetchChain{fetchers: []Fetcher{failing}}
+	if err := chain.fetch(pageURL, hasEpisodes); err == nil || errors.Is(err, errNoContent) || !strings.Contains(err.Error(), "página inesperada") {
+		t.Errorf("sin páginas obtenidas se esperaba el error de la estrategia, se obtuvo %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultFranchiseMax entradas de una franquicia que se recorren como máximo por defecto
+const defaultFranchiseMax = 20
+
+// collectFranchise recorre el grafo de relacionados a partir del anime indicado.
+// Devuelve las entradas sin duplicados y ordenadas por emisión. Con verbose en false no se muestra el progreso.
+func collectFranchise(animeLink string, maxEntries int, verbose bool) ([]*AnimeDetails, error) {
+	var entries []*AnimeDetails
+	visited := map[string]bool{animeLink: true}
+	queue := []string{animeLink}
+
+	for len(queue) > 0 {
+		if maxEntries > 0 && len(entries) >= maxEntries {
+			if verbose {
+				fmt.Printf("⚠️  Se alcanzó el límite de %d entradas de la franquicia (--franchise-max)\n", maxEntries)
+			}
+			break
+		}
+
+		link := queue[0]
+		queue = queue[1:]
+
+		details, err := getAnimeDetails(link)
+		if err != nil {
+			// Sin la entrada inicial no hay franquicia; las demás se pueden omitir
+			if len(entries) == 0 {
+				return nil, err
+			}
+			if verbose {
+				fmt.Printf("⚠️  No se pudo obtener %s: %v\n", link, err)
+			}
+			continue
+		}
+
+		if verbose {
+			fmt.Printf("🔗 %s\n", animeLabel(Anime{Name: details.Title, Type: details.Type}))
+		}
+		entries = append(entries, details)
+
+		for _, related := range details.Related {
+			if !strings.HasPrefix(related.Link, "/anime/") || visited[related.Link] {
+				continue
+			}
+			visited[related.Link] = true
+			queue = append(queue, related.Link)
+		}
+	}
+
+	return orderFranchise(entries), nil
+}
+
+// orderFranchise ordena las entradas respetando precuelas y secuelas.
+// Entre entradas sin relación de orden se usa el id de AnimeFLV, que crece con cada alta.
+func orderFranchise(entries []*AnimeDetails) []*AnimeDetails {
+	index := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		index[entry.Link] = i
+	}
+
+	// after[a] contiene las entradas que van después de a
+	after := make([][]int, len(entries))
+	pending := make([]int, len(entries))
+	addEdge := func(before, next int) {
+		if before == next {
+			return
+		}
+		after[before] = append(after[before], next)
+		pending[next]++
+	}
+
+	for i, entry := range entries {
+		for _, related := range entry.Related {
+			j, exists := index[related.Link]
+			if !exists {
+				continue
+			}
+
+			switch normalizeBrowseValue(related.Relation) {
+			case "precuela", "prequel":
+				addEdge(j, i)
+			case "secuela", "sequel":
+				addEdge(i, j)
+			}
+		}
+	}
+
+	less := func(a, b int) bool {
+		idA, idB := entries[a].ID, entries[b].ID
+		if idA != idB && idA > 0 && idB > 0 {
+			return idA < idB
+		}
+		return a < b
+	}
+
+	// Kahn: en cada paso se elige la entrada disponible más antigua
+	var ready []int
+	for i := range entries {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	ordered := make([]*AnimeDetails, 0, len(entries))
+	placed := make([]bool, len(entries))
+	for len(ordered) < len(entries) {
+		if len(ready) == 0 {
+			// Ciclo en las relaciones (datos inconsistentes): se libera la más antigua pendiente
+			for i := range entries {
+				if !placed[i] && (len(ready) == 0 || less(i, ready[0])) {
+					ready = []int{i}
+				}
+			}
+		}
+
+		sort.Slice(ready, func(a, b int) bool { return less(ready[a], ready[b]) })
+		current := ready[0]
+		ready = ready[1:]
+
+		if placed[current] {
+			continue
+		}
+		placed[current] = true
+		ordered = append(ordered, entries[current])
+
+		for _, next := range after[current] {
+			pending[next]--
+			if pending[next] == 0 && !placed[next] {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	return ordered
+}
+
+// getFranchiseEpisodes obtiene los episodios de todas las entradas de la franquicia.
+// Cada episodio queda marcado con el título de su entrada para separar las temporadas.
+func getFranchiseEpisodes(entries []*AnimeDetails, verbose bool) []Episode {
+	var episodesList []Episode
+
+	for _, entry := range entries {
+		_, episodes, err := getLinksEpisodes(entry.Title, entry.Link, verbose)
+		if err != nil {
+			if verbose {
+				fmt.Printf("⚠️  Error obteniendo episodios de %s: %v\n", entry.Title, err)
+			}
+			continue
+		}
+
+		for _, episode := range episodes {
+			episode.Anime = entry.Title
+			episodesList = append(episodesList, episode)
+		}
+	}
+
+	return episodesList
+}
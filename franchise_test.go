@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// franchiseEntry arma la ficha de una entrada de la franquicia con sus relacionados
+func franchiseEntry(link string, id int, related ...RelatedAnime) *AnimeDetails {
+	return &AnimeDetails{ID: id, Title: link, Link: link, Related: related}
+}
+
+func TestOrderFranchise(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []*AnimeDetails // en el orden en que se recorrió el grafo
+		want    []string
+	}{
+		{
+			name: "cadena de precuelas y secuelas contra el orden de los ids",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/temporada-2", 10,
+					RelatedAnime{Link: "/anime/temporada-1", Relation: "Precuela"},
+					RelatedAnime{Link: "/anime/temporada-3", Relation: "Secuela"}),
+				franchiseEntry("/anime/temporada-1", 20,
+					RelatedAnime{Link: "/anime/temporada-2", Relation: "Secuela"}),
+				franchiseEntry("/anime/temporada-3", 5,
+					RelatedAnime{Link: "/anime/temporada-2", Relation: "Precuela"}),
+			},
+			want: []string{"/anime/temporada-1", "/anime/temporada-2", "/anime/temporada-3"},
+		},
+		{
+			name: "relación declarada de un solo lado",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/secuela", 1),
+				franchiseEntry("/anime/original", 2,
+					RelatedAnime{Link: "/anime/secuela", Relation: "Secuela"}),
+			},
+			want: []string{"/anime/original", "/anime/secuela"},
+		},
+		{
+			name: "historias paralelas ordenadas por id",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/serie", 100,
+					RelatedAnime{Link: "/anime/serie-2", Relation: "Secuela"},
+					RelatedAnime{Link: "/anime/ova", Relation: "Historia Paralela"},
+					RelatedAnime{Link: "/anime/pelicula", Relation: "Spin-off"}),
+				franchiseEntry("/anime/serie-2", 300,
+					RelatedAnime{Link: "/anime/serie", Relation: "Precuela"}),
+				franchiseEntry("/anime/pelicula", 400,
+					RelatedAnime{Link: "/anime/serie", Relation: "Historia Original"}),
+				franchiseEntry("/anime/ova", 200,
+					RelatedAnime{Link: "/anime/serie", Relation: "Historia Paralela"}),
+			},
+			want: []string{"/anime/serie", "/anime/ova", "/anime/serie-2", "/anime/pelicula"},
+		},
+		{
+			name: "historia paralela anterior a la serie",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/serie", 50,
+					RelatedAnime{Link: "/anime/serie-2", Relation: "Secuela"},
+					RelatedAnime{Link: "/anime/especial", Relation: "Historia Paralela"}),
+				franchiseEntry("/anime/serie-2", 60,
+					RelatedAnime{Link: "/anime/serie", Relation: "Precuela"}),
+				franchiseEntry("/anime/especial", 10),
+			},
+			want: []string{"/anime/especial", "/anime/serie", "/anime/serie-2"},
+		},
+		{
+			name: "sin id se respeta el orden del recorrido",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/b", 0),
+				franchiseEntry("/anime/a", 0),
+				franchiseEntry("/anime/c", 0,
+					RelatedAnime{Link: "/anime/b", Relation: "Precuela"}),
+			},
+			want: []string{"/anime/b", "/anime/a", "/anime/c"},
+		},
+		{
+			name: "relaciones en inglés y fuera de la franquicia",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/segunda", 1,
+					RelatedAnime{Link: "/anime/primera", Relation: "Prequel"},
+					RelatedAnime{Link: "/anime/no-recorrida", Relation: "Sequel"}),
+				franchiseEntry("/anime/primera", 2),
+			},
+			want: []string{"/anime/primera", "/anime/segunda"},
+		},
+		{
+			name: "ciclo en las relaciones",
+			entries: []*AnimeDetails{
+				franchiseEntry("/anime/b", 2,
+					RelatedAnime{Link: "/anime/a", Relation: "Secuela"}),
+				franchiseEntry("/anime/a", 1,
+					RelatedAnime{Link: "/anime/b", Relation: "Secuela"}),
+				franchiseEntry("/anime/c", 3,
+					RelatedAnime{Link: "/anime/b", Relation: "Precuela"}),
+			},
+			want: []string{"/anime/a", "/anime/b", "/anime/c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, entry := range orderFranchise(tt.entries) {
+				got = append(got, entry.Link)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orden = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hlsWorkers cantidad de segmentos descargados en paralelo
+const hlsWorkers = 8
+
+// hlsVariant representa una variante de un master playlist
+type hlsVariant struct {
+	Bandwidth int
+	URI       string
+}
+
+// hlsKey representa la clave de cifrado de un segmento
+type hlsKey struct {
+	Method string
+	URI    string
+	IV     []byte
+}
+
+// hlsSegment representa un segmento de un media playlist
+type hlsSegment struct {
+	URI      string
+	Sequence int
+	Key      *hlsKey
+}
+
+// isHLS indica si el enlace apunta a un playlist m3u8
+func isHLS(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasSuffix(strings.ToLower(u.Path), ".m3u8")
+}
+
+// fetchHLSResource descarga un playlist, segmento o clave con los headers del proveedor.
+// Los segmentos no pasan por el limitador de peticiones.
+func fetchHLSResource(link string, headers map[string]string, segment bool) ([]byte, error) {
+	req, err := newBrowserRequest("GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if segment {
+		req = withoutRateLimit(req)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := doWithRetry(newHTTPClient(60*time.Second), req)
+	if err != nil {
+		return nil, fmt.Errorf("error haciendo request HTTP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("error HTTP: código de estado %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// parseHLSAttributes parsea la lista de atributos de una etiqueta (CLAVE=valor,...)
+func parseHLSAttributes(text string) map[string]string {
+	attributes := make(map[string]string)
+
+	for len(text) > 0 {
+		key, rest, found := strings.Cut(text, "=")
+		if !found {
+			break
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// Los valores entre comillas pueden contener comas
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		attributes[strings.TrimSpace(key)] = value
+		text = strings.TrimPrefix(rest, ",")
+	}
+
+	return attributes
+}
+
+// resolveHLSURI convierte una URI relativa del playlist en absoluta
+func resolveHLSURI(base *url.URL, uri string) string {
+	ref, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return uri
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// parseM3U8 parsea un playlist y devuelve sus variantes (master) o sus segmentos (media)
+func parseM3U8(content string, base *url.URL) ([]hlsVariant, []hlsSegment, error) {
+	if !strings.HasPrefix(strings.TrimSpace(content), "#EXTM3U") {
+		return nil, nil, fmt.Errorf("playlist m3u8 inválido")
+	}
+
+	var variants []hlsVariant
+	var segments []hlsSegment
+	var currentKey *hlsKey
+	sequence := 0
+	pendingVariant := -1
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			attributes := parseHLSAttributes(strings.TrimPrefix(line, "#EXT-X-STREAM-INF:"))
+			bandwidth, _ := strconv.Atoi(attributes["BANDWIDTH"])
+			variants = append(variants, hlsVariant{Bandwidth: bandwidth})
+			pendingVariant = len(variants) - 1
+
+		// Segmentos fMP4 (init por separado) o partes de un único archivo: unirlos como .ts daría un video roto
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			return nil, nil, fmt.Errorf("playlist HLS no soportado: segmentos fMP4 (#EXT-X-MAP)")
+
+		case strings.HasPrefix(line, "#EXT-X-BYTERANGE:"):
+			return nil, nil, fmt.Errorf("playlist HLS no soportado: segmentos por rango de bytes (#EXT-X-BYTERANGE)")
+
+		case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+			sequence, _ = strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"))
+
+		case strings.HasPrefix(line, "#EXT-X-KEY:"):
+			attributes := parseHLSAttributes(strings.TrimPrefix(line, "#EXT-X-KEY:"))
+			if attributes["METHOD"] == "NONE" {
+				currentKey = nil
+				continue
+			}
+
+			if attributes["METHOD"] != "AES-128" {
+				return nil, nil, fmt.Errorf("método de cifrado no soportado: %s", attributes["METHOD"])
+			}
+
+			currentKey = &hlsKey{
+				Method: attributes["METHOD"],
+				URI:    resolveHLSURI(base, attributes["URI"]),
+			}
+
+			if iv := attributes["IV"]; iv != "" {
+				decoded, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(iv, "0x"), "0X"))
+				if err != nil || len(decoded) != aes.BlockSize {
+					return nil, nil, fmt.Errorf("IV inválido: %s", iv)
+				}
+				currentKey.IV = decoded
+			}
+
+		case strings.HasPrefix(line, "#"):
+			continue
+
+		default:
+			if pendingVariant >= 0 {
+				variants[pendingVariant].URI = resolveHLSURI(base, line)
+				pendingVariant = -1
+				continue
+			}
+
+			segments = append(segments, hlsSegment{
+				URI:      resolveHLSURI(base, line),
+				Sequence: sequence,
+				Key:      currentKey,
+			})
+			sequence++
+		}
+	}
+
+	return variants, segments, scanner.Err()
+}
+
+// loadHLSSegments descarga el playlist y, si es un master, sigue la variante de mayor calidad
+func loadHLSSegments(playlistURL string, headers map[string]string) ([]hlsSegment, error) {
+	for range 3 {
+		content, err := fetchHLSResource(playlistURL, headers, false)
+		if err != nil {
+			return nil, err
+		}
+
+		base, err := url.Parse(playlistURL)
+		if err != nil {
+			return nil, fmt.Errorf("URL de playlist inválida: %v", err)
+		}
+
+		variants, segments, err := parseM3U8(string(content), base)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(variants) == 0 {
+			if len(segments) == 0 {
+				return nil, fmt.Errorf("playlist sin segmentos")
+			}
+			return segments, nil
+		}
+
+		playlistURL = bestHLSVariant(variants).URI
+	}
+
+	return nil, fmt.Errorf("demasiados niveles de master playlist")
+}
+
+// bestHLSVariant elige la variante de mayor calidad (mayor BANDWIDTH) del master playlist
+func bestHLSVariant(variants []hlsVariant) hlsVariant {
+	best := variants[0]
+	for _, variant := range variants[1:] {
+		if variant.Bandwidth > best.Bandwidth {
+			best = variant
+		}
+	}
+
+	return best
+}
+
+// decryptHLSSegment descifra un segmento AES-128 en modo CBC
+func decryptHLSSegment(data, key []byte, segment hlsSegment) ([]byte, error) {
+	// Una respuesta que no es la clave (ej. una página de error) no tiene 16 bytes
+	if len(key) != 16 {
+		return nil, fmt.Errorf("clave AES-128 inválida: se esperaban 16 bytes y se recibieron %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("clave AES inválida: %v", err)
+	}
+
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("segmento cifrado con tamaño inválido")
+	}
+
+	// Sin IV explícito se usa el número de secuencia del segmento
+	iv := segment.Key.IV
+	if iv == nil {
+		iv = make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(iv[8:], uint64(segment.Sequence))
+	}
+
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+
+	// Quitar el relleno PKCS#7
+	if len(decrypted) > 0 {
+		padding := int(decrypted[len(decrypted)-1])
+		if padding > 0 && padding <= aes.BlockSize && bytes.HasSuffix(decrypted, bytes.Repeat([]byte{byte(padding)}, padding)) {
+			decrypted = decrypted[:len(decrypted)-padding]
+		}
+	}
+
+	return decrypted, nil
+}
+
+// downloadHLS descarga todos los segmentos de un playlist y los concatena en un archivo TS
+func downloadHLS(resolved *Resolved, destination string) error {
+	segments, err := loadHLSSegments(resolved.URL, resolved.Headers)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("error creando archivo: %v", err)
+	}
+	defer file.Close()
+
+	// Las claves se comparten entre segmentos, se descargan una sola vez
+	var keysMutex sync.Mutex
+	keys := make(map[string][]byte)
+	getKey := func(uri string) ([]byte, error) {
+		keysMutex.Lock()
+		defer keysMutex.Unlock()
+
+		if key, exists := keys[uri]; exists {
+			return key, nil
+		}
+
+		key, err := fetchHLSResource(uri, resolved.Headers, false)
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo clave: %v", err)
+		}
+		keys[uri] = key
+		return key, nil
+	}
+
+	// Descargar por bloques para limitar la memoria y conservar el orden al escribir
+	for start := 0; start < len(segments); start += hlsWorkers {
+		end := min(start+hlsWorkers, len(segments))
+		results := make([][]byte, end-start)
+		errs := make([]error, end-start)
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				segment := segments[i]
+				data, err := fetchHLSResource(segment.URI, resolved.Headers, true)
+				if err == nil && segment.Key != nil {
+					var key []byte
+					key, err = getKey(segment.Key.URI)
+					if err == nil {
+						data, err = decryptHLSSegment(data, key, segment)
+					}
+				}
+
+				results[i-start] = data
+				errs[i-start] = err
+			}(i)
+		}
+		wg.Wait()
+
+		for i, data := range results {
+			if errs[i] != nil {
+				return fmt.Errorf("error en segmento %d/%d: %v", start+i+1, len(segments), errs[i])
+			}
+
+			if _, err := file.Write(data); err != nil {
+				return fmt.Errorf("error escribiendo archivo: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// hlsBase URL desde la que se resuelven las URIs relativas de los playlists de prueba
+var hlsBase, _ = url.Parse("https://cdn.example.com/hls/ep1/index.m3u8")
+
+func TestParseM3U8Master(t *testing.T) {
+	variants, segments, err := parseM3U8(loadFixture(t, "hls", "master.m3u8"), hlsBase)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(segments) != 0 {
+		t.Errorf("%d segmentos en un master playlist", len(segments))
+	}
+
+	want := []hlsVariant{
+		{Bandwidth: 800000, URI: "https://cdn.example.com/hls/ep1/360p/index.m3u8"},
+		{Bandwidth: 2800000, URI: "https://cdn.example.com/hls/ep1/720p/index.m3u8"},
+		{Bandwidth: 1400000, URI: "https://cdn2.example.com/480p/index.m3u8"},
+	}
+	if len(variants) != len(want) {
+		t.Fatalf("%d variantes; se esperaban %d: %+v", len(variants), len(want), variants)
+	}
+	for i := range want {
+		if variants[i] != want[i] {
+			t.Errorf("variante %d = %+v, se esperaba %+v", i, variants[i], want[i])
+		}
+	}
+
+	if best := bestHLSVariant(variants); best.URI != want[1].URI {
+		t.Errorf("mejor variante = %s, se esperaba %s", best.URI, want[1].URI)
+	}
+}
+
+func TestParseM3U8Media(t *testing.T) {
+	variants, segments, err := parseM3U8(loadFixture(t, "hls", "media.m3u8"), hlsBase)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(variants) != 0 {
+		t.Errorf("%d variantes en un media playlist", len(variants))
+	}
+
+	tests := []struct {
+		uri      string
+		sequence int
+		keyURI   string
+		iv       []byte
+	}{
+		{uri: "https://cdn.example.com/hls/ep1/seg-7.ts", sequence: 7},
+		{uri: "https://cdn.example.com/hls/ep1/seg-8.ts", sequence: 8, keyURI: "https://cdn.example.com/keys/key1.bin"},
+		{
+			uri:      "https://cdn.example.com/hls/other/seg-9.ts?token=abc",
+			sequence: 9,
+			keyURI:   "https://cdn.example.com/hls/ep1/key2.bin",
+			iv:       []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		},
+		{uri: "https://cdn2.example.com/seg-10.ts", sequence: 10},
+	}
+
+	if len(segments) != len(tests) {
+		t.Fatalf("%d segmentos; se esperaban %d", len(segments), len(tests))
+	}
+	for i, tt := range tests {
+		segment := segments[i]
+		if segment.URI != tt.uri || segment.Sequence != tt.sequence {
+			t.Errorf("segmento %d = %s (secuencia %d), se esperaba %s (secuencia %d)", i, segment.URI, segment.Sequence, tt.uri, tt.sequence)
+		}
+
+		if tt.keyURI == "" {
+			if segment.Key != nil {
+				t.Errorf("segmento %d cifrado con %+v; se esperaba sin clave", i, segment.Key)
+			}
+			continue
+		}
+		if segment.Key == nil || segment.Key.URI != tt.keyURI || !bytes.Equal(segment.Key.IV, tt.iv) {
+			t.Errorf("clave del segmento %d = %+v, se esperaba %s con IV %x", i, segment.Key, tt.keyURI, tt.iv)
+		}
+	}
+}
+
+func TestParseM3U8Unsupported(t *testing.T) {
+	tests := []struct {
+		fixture string
+		message string
+	}{
+		{"fmp4.m3u8", "fMP4"},
+		{"byterange.m3u8", "rango de bytes"},
+		{"sample-aes.m3u8", "SAMPLE-AES"},
+		{"invalid.m3u8", "inválido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			_, _, err := parseM3U8(loadFixture(t, "hls", tt.fixture), hlsBase)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %v; se esperaba uno que mencione %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseHLSAttributes(t *testing.T) {
+	attributes := parseHLSAttributes(`BANDWIDTH=2800000,CODECS="avc1.4d401f,mp4a.40.2",RESOLUTION=1280x720,URI="a=b.key"`)
+
+	want := map[string]string{
+		"BANDWIDTH":  "2800000",
+		"CODECS":     "avc1.4d401f,mp4a.40.2",
+		"RESOLUTION": "1280x720",
+		"URI":        "a=b.key",
+	}
+	if len(attributes) != len(want) {
+		t.Errorf("atributos = %v, se esperaba %v", attributes, want)
+	}
+	for key, value := range want {
+		if attributes[key] != value {
+			t.Errorf("%s = %q, se esperaba %q", key, attributes[key], value)
+		}
+	}
+}
+
+// encryptHLSSegment cifra como un servidor HLS: AES-128-CBC con relleno PKCS#7
+func encryptHLSSegment(t *testing.T, plain, key, iv []byte) []byte {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	padded := append(bytes.Clone(plain), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+	return encrypted
+}
+
+func TestDecryptHLSSegment(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	explicitIV := []byte("fedcba9876543210")
+
+	// Sin IV explícito el IV es el número de secuencia en big endian
+	sequenceIV := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(sequenceIV[8:], 42)
+
+	tests := []struct {
+		name    string
+		plain   []byte
+		segment hlsSegment
+		iv      []byte
+	}{
+		{
+			name:    "IV por secuencia",
+			plain:   []byte("paquete TS de prueba con relleno"),
+			segment: hlsSegment{Sequence: 42, Key: &hlsKey{Method: "AES-128"}},
+			iv:      sequenceIV,
+		},
+		{
+			name:    "IV explícito",
+			plain:   []byte("otro segmento"),
+			segment: hlsSegment{Sequence: 42, Key: &hlsKey{Method: "AES-128", IV: explicitIV}},
+			iv:      explicitIV,
+		},
+		{
+			// Un bloque completo de relleno cuando el contenido ya es múltiplo de 16
+			name:    "relleno de bloque completo",
+			plain:   []byte("exactamente 16 b"),
+			segment: hlsSegment{Sequence: 0, Key: &hlsKey{Method: "AES-128", IV: explicitIV}},
+			iv:      explicitIV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted := encryptHLSSegment(t, tt.plain, key, tt.iv)
+
+			decrypted, err := decryptHLSSegment(encrypted, key, tt.segment)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if !bytes.Equal(decrypted, tt.plain) {
+				t.Errorf("descifrado = %q, se esperaba %q", decrypted, tt.plain)
+			}
+		})
+	}
+}
+
+func TestDecryptHLSSegmentErrors(t *testing.T) {
+	segment := hlsSegment{Key: &hlsKey{Method: "AES-128"}}
+
+	// Una página de error en lugar de la clave
+	_, err := decryptHLSSegment(make([]byte, 32), []byte("<html>403</html> Forbidden"), segment)
+	if err == nil || !strings.Contains(err.Error(), "16 bytes") {
+		t.Errorf("error = %v; se esperaba uno sobre el largo de la clave", err)
+	}
+
+	_, err = decryptHLSSegment(make([]byte, 20), []byte("0123456789abcdef"), segment)
+	if err == nil {
+		t.Error("se esperaba error con un segmento que no es múltiplo del bloque")
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// latestEpisodes extrae los últimos episodios publicados de la portada
+func (p *selectorProfile) latestEpisodes(doc *goquery.Document) []Episode {
+	var episodesList []Episode
+
+	if p.Latest.Item == "" {
+		return nil
+	}
+
+	doc.Find(p.Latest.Item).Each(func(i int, s *goquery.Selection) {
+		episodeLink := s.Find(p.Latest.Link).First()
+		href, exists := episodeLink.Attr("href")
+		animeName := selectionText(episodeLink, p.Latest.Anime)
+		episodeName := selectionText(episodeLink, p.Latest.Episode)
+
+		if exists && animeName != "" {
+			episodesList = append(episodesList, Episode{
+				Name:   episodeName,
+				Link:   href,
+				Anime:  animeName,
+				Number: parseEpisodeNumber(episodeName),
+			})
+		}
+	})
+
+	return episodesList
+}
+
+// parseEpisodeNumber obtiene el número del texto del episodio (ej. "Episodio 12"); 0 si no hay
+func parseEpisodeNumber(text string) int {
+	matches := episodeNumberRegex.FindStringSubmatch(strings.TrimSpace(text))
+	if len(matches) < 2 {
+		return 0
+	}
+
+	number, _ := strconv.Atoi(matches[1])
+	return number
+}
+
+// getLatestEpisodes obtiene los últimos episodios publicados desde la portada de AnimeFLV
+func getLatestEpisodes() ([]Episode, error) {
+	var episodesList []Episode
+
+	// La portada cambia a cada rato: no se reutiliza la copia en caché
+	pageCache.Delete(urlBase + "/")
+
+	err := pageFetcher.fetch(urlBase+"/", func(doc *goquery.Document) bool {
+		episodesList = selectors.latestEpisodes(doc)
+		return len(episodesList) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return episodesList, nil
+}
+
+// latestEpisodeLabel describe un episodio de la portada ("One Piece - Episodio 1125")
+func latestEpisodeLabel(episode Episode) string {
+	if episode.Name == "" {
+		return episode.Anime
+	}
+
+	return fmt.Sprintf("%s - %s", episode.Anime, episode.Name)
+}
+
+// runLatest implementa el comando `latest`: lista los últimos episodios y opcionalmente sus enlaces
+func runLatest(args []string) error {
+	fs := flag.NewFlagSet("latest", flag.ExitOnError)
+	withLinks := fs.Bool("links", false, "Obtener también los enlaces de descarga de cada episodio")
+	limit := fs.Int("limit", 0, "Mostrar solo los N episodios más recientes (0 = todos)")
+	asJSON := fs.Bool("json", false, "Mostrar el resultado en formato JSON")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	fs.Parse(args)
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+	selectors = profile
+
+	episodesList, err := getLatestEpisodes()
+	if err != nil {
+		return fmt.Errorf("error obteniendo los últimos episodios: %w", err)
+	}
+
+	if len(episodesList) == 0 {
+		return fmt.Errorf("no se encontraron episodios en la portada")
+	}
+
+	if *limit > 0 && len(episodesList) > *limit {
+		episodesList = episodesList[:*limit]
+	}
+
+	results := make([]ExportEpisode, len(episodesList))
+	for i, episode := range episodesList {
+		results[i].Episode = episode
+
+		if !*asJSON {
+			fmt.Printf("%d.- %s, enlace: %s", i+1, latestEpisodeLabel(episode), episode.Link)
+		}
+
+		if !*withLinks {
+			if !*asJSON {
+				fmt.Println()
+			}
+			continue
+		}
+
+		downloadList, err := fetchEpisodeDownloads(episode)
+		if err != nil {
+			if !*asJSON {
+				fmt.Printf(" ❌ Error: %v\n", err)
+			}
+			continue
+		}
+		results[i].Downloads = downloadList
+
+		if !*asJSON {
+			printEpisodeResult(downloadList)
+			for _, download := range downloadList {
+				fmt.Printf("      • %s: %s\n", download.ProviderName, download.DownloadURL)
+			}
+		}
+	}
+
+	if *asJSON {
+		content, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error generando JSON: %v", err)
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+
+	pageCache.printStats()
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build !unix && !windows
+
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lockFile sin lock del sistema (ej. plan9, wasip1): el lock pertenece al proceso cuyo PID
+// figura en el archivo. Es solo una aproximación: no es atómico (dos procesos pueden leer el
+// archivo vacío a la vez) y estas plataformas no permiten saber si el PID sigue vivo, así que
+// tras una caída el archivo queda tomado hasta borrarlo a mano.
+func lockFile(f *os.File) error {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil
+	}
+
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil || pid == os.Getpid() {
+		return nil
+	}
+
+	return errSyncLocked
+}
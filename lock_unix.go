@@ -0,0 +1,19 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile toma un lock exclusivo (flock) sin esperar; el sistema lo libera al cerrar el archivo o al morir el proceso
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errSyncLocked
+	}
+
+	return err
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset byte bloqueado, lejos del contenido: en Windows el lock es obligatorio
+// y bloquear el inicio impediría a otros procesos leer quién tiene el lock
+const lockOffset = 1 << 30
+
+// lockFile toma un lock exclusivo (LockFileEx) sin esperar; el sistema lo libera al cerrar el archivo o al morir el proceso
+func lockFile(f *os.File) error {
+	overlapped := &windows.Overlapped{Offset: lockOffset}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errSyncLocked
+	}
+
+	return err
+}
@@ -2,209 +2,126 @@ package main
 
 import (
 	"bufio"
-	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/chromedp/chromedp"
 )
 
 const urlBase = "https://www3.animeflv.net"
 
+// defaultMaxSearchPages páginas de resultados que se recorren como máximo por defecto
+const defaultMaxSearchPages = 5
+
 // Anime representa un anime encontrado en la búsqueda
 type Anime struct {
-	Name string
-	Link string
+	Name     string  `json:"name"`
+	Link     string  `json:"link"`
+	Type     string  `json:"type,omitempty"` // TV, OVA, Película, Especial
+	Cover    string  `json:"cover,omitempty"`
+	Rating   float64 `json:"rating,omitempty"`
+	Synopsis string  `json:"synopsis,omitempty"`
 }
 
-// Episode representa un episodio de anime
+// Episode representa un episodio de anime.
+// Anime y Number solo se completan cuando el episodio no viene de la página del anime (ej. portada).
 type Episode struct {
-	Name string
-	Link string
+	Name   string `json:"name"`
+	Link   string `json:"link"`
+	Anime  string `json:"anime,omitempty"`
+	Number int    `json:"number,omitempty"`
 }
 
 // Download representa un enlace de descarga
 type Download struct {
-	ProviderName string
-	DownloadURL  string
+	ProviderName string    `json:"provider"`
+	DownloadURL  string    `json:"url"`
+	Direct       *Resolved `json:"direct,omitempty"`
+	Status       string    `json:"status,omitempty"`
 }
 
-// searchAnime busca animes basado en el texto de búsqueda
-func searchAnime(searchText string) ([]Anime, error) {
-	var animesList []Anime
+// searchAnime busca animes basado en el texto de búsqueda.
+// Recorre la paginación hasta maxPages páginas (0 = todas).
+func searchAnime(searchText string, maxPages int) ([]Anime, error) {
+	return browseAnime(BrowseFilter{Query: searchText}, maxPages)
+}
 
-	// Construir URL con parámetros de búsqueda
-	searchURL := fmt.Sprintf("%s/browse?q=%s", urlBase, url.QueryEscape(searchText))
+// absoluteURL resuelve un enlace relativo de AnimeFLV contra urlBase
+func absoluteURL(link string) string {
+	base, _ := url.Parse(urlBase + "/")
 
-	// Realizar petición HTTP GET
-	resp, err := http.Get(searchURL)
+	ref, err := url.Parse(link)
 	if err != nil {
-		return nil, fmt.Errorf("error haciendo petición: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("error accediendo a la página, código de estado: %d", resp.StatusCode)
+		return link
 	}
 
-	// Parsear HTML con goquery
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error parseando HTML: %v", err)
-	}
+	return base.ResolveReference(ref).String()
+}
 
-	// Encontrar elementos específicos
-	doc.Find(".ListAnimes .Anime").Each(func(i int, s *goquery.Selection) {
-		animeLink := s.Find("a")
-		animeName := animeLink.Find(".Title").Text()
-		href, exists := animeLink.Attr("href")
+// isAnimeFLVHost indica si el host es de AnimeFLV (el de urlBase u otro subdominio de animeflv.net)
+func isAnimeFLVHost(host string) bool {
+	base, _ := url.Parse(urlBase)
+	host = strings.ToLower(host)
 
-		if exists && animeName != "" {
-			animesList = append(animesList, Anime{
-				Name: strings.TrimSpace(animeName),
-				Link: href,
-			})
-		}
-	})
-
-	return animesList, nil
+	return host == base.Hostname() || host == "animeflv.net" || strings.HasSuffix(host, ".animeflv.net")
 }
 
-// getDownloadLinksEpisode obtiene los enlaces de descarga de un episodio específico
-func getDownloadLinksEpisode(episodeLink string) ([]Download, error) {
-	var downloadList []Download
+// normalizeAnimeType usa "TV" para las series, que AnimeFLV etiqueta como "Anime"
+func normalizeAnimeType(animeType string) string {
+	if strings.EqualFold(animeType, "Anime") {
+		return "TV"
+	}
 
-	// Configurar chromedp con opciones más robustas
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-javascript", false), // Habilitamos JS ya que puede ser necesario
-		chromedp.Flag("disable-web-security", true),
-		chromedp.Flag("disable-features", "VizDisplayCompositor"),
-		chromedp.Flag("disable-background-timer-throttling", true),
-		chromedp.Flag("disable-backgrounding-occluded-windows", true),
-		chromedp.Flag("disable-renderer-backgrounding", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("disable-plugins", true),
-		chromedp.Flag("disable-images", true),
-		chromedp.Flag("disable-default-apps", true),
-	)
-
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
-
-	// Configurar contexto con logging reducido
-	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(func(string, ...interface{}) {}))
-	defer cancel()
-
-	// Timeout más corto
-	ctx, cancel = context.WithTimeout(ctx, 20*time.Second)
-	defer cancel()
-
-	var htmlContent string
-
-	// Intentar obtener el contenido con manejo de errores mejorado
-	err := chromedp.Run(ctx,
-		chromedp.Navigate(urlBase+episodeLink),
-		chromedp.Sleep(2*time.Second), // Esperar carga
-		chromedp.OuterHTML("html", &htmlContent),
-	)
+	return animeType
+}
 
+// parseRating convierte la puntuación de la tarjeta (ej. "4.5") en número; 0 si no hay
+func parseRating(text string) float64 {
+	rating, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
 	if err != nil {
-		// Si ChromeDP falla, intentar con HTTP simple
-		return getDownloadLinksWithHTTP(episodeLink)
+		return 0
 	}
 
-	// Parsear el contenido HTML
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil, fmt.Errorf("error parseando HTML: %v", err)
-	}
-
-	// Obtener tabla de descargas
-	doc.Find("tbody tr").Each(func(i int, s *goquery.Selection) {
-		tds := s.Find("td")
-		if tds.Length() >= 4 {
-			providerName := strings.TrimSpace(tds.Eq(0).Text())
-			downloadLink := tds.Eq(3).Find("a")
-			href, exists := downloadLink.Attr("href")
-
-			if exists && providerName != "" {
-				downloadList = append(downloadList, Download{
-					ProviderName: providerName,
-					DownloadURL:  href,
-				})
-			}
-		}
-	})
-
-	return downloadList, nil
+	return rating
 }
 
-// getDownloadLinksWithHTTP intenta obtener los enlaces usando solo HTTP (fallback)
-func getDownloadLinksWithHTTP(episodeLink string) ([]Download, error) {
-	var downloadList []Download
-
-	// Crear cliente HTTP con headers que simulen un navegador real
-	client := &http.Client{
-		Timeout: 15 * time.Second,
+// animeLabel describe un anime en una línea con su tipo y puntuación si se conocen
+func animeLabel(anime Anime) string {
+	var details []string
+	if anime.Type != "" {
+		details = append(details, anime.Type)
 	}
-
-	req, err := http.NewRequest("GET", urlBase+episodeLink, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creando request: %v", err)
+	if anime.Rating > 0 {
+		details = append(details, fmt.Sprintf("⭐ %.1f", anime.Rating))
 	}
 
-	// Agregar headers para simular un navegador real
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "es-ES,es;q=0.8,en-US;q=0.5,en;q=0.3")
-	req.Header.Set("Accept-Encoding", "gzip, deflate")
-	req.Header.Set("Connection", "keep-alive")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error haciendo request HTTP: %v", err)
+	if len(details) == 0 {
+		return anime.Name
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("error HTTP: código de estado %d", resp.StatusCode)
-	}
+	return fmt.Sprintf("%s (%s)", anime.Name, strings.Join(details, ", "))
+}
 
-	// Parsear HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error parseando HTML con HTTP: %v", err)
-	}
-
-	// Buscar tabla de descargas
-	doc.Find("tbody tr").Each(func(i int, s *goquery.Selection) {
-		tds := s.Find("td")
-		if tds.Length() >= 4 {
-			providerName := strings.TrimSpace(tds.Eq(0).Text())
-			downloadLink := tds.Eq(3).Find("a")
-			href, exists := downloadLink.Attr("href")
-
-			if exists && providerName != "" {
-				downloadList = append(downloadList, Download{
-					ProviderName: providerName,
-					DownloadURL:  href,
-				})
-			}
-		}
+// getDownloadLinksEpisode obtiene los enlaces de descarga de un episodio específico
+func getDownloadLinksEpisode(episodeLink string) ([]Download, error) {
+	var downloadList []Download
+
+	err := pageFetcher.fetch(urlBase+episodeLink, func(doc *goquery.Document) bool {
+		// Tabla de descargas más los servidores de streaming que pueden usarse como mirrors
+		downloadList = appendUniqueDownloads(selectors.downloadTable(doc), extractStreamingServers(doc))
+		return len(downloadList) > 0
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return downloadList, nil
 }
@@ -227,176 +144,178 @@ func sanitizeFilename(filename string) string {
 	return cleaned
 }
 
-// writeDownloadsToFile escribe los enlaces de descarga a un archivo de texto
-func writeDownloadsToFile(animeName string, episodes []Episode, allDownloads map[string][]Download) error {
+// writeDownloadsToFile escribe los enlaces de descarga a un archivo de texto, JSON y metalink en dir.
+// franchise contiene las entradas de la franquicia en orden (vacío si es un solo anime).
+// Devuelve la ruta del archivo de texto.
+func writeDownloadsToFile(dir, animeName string, details *AnimeDetails, franchise []*AnimeDetails, episodes []Episode, allDownloads map[string][]Download) (string, error) {
+	export := newExportFile(animeName, episodes, allDownloads)
+	export.Details = details
+	export.Franchise = franchise
+
 	// Crear nombre de archivo limpio
-	filename := sanitizeFilename(animeName) + ".txt"
+	return writeExportFiles(filepath.Join(dir, sanitizeFilename(animeName)), export)
+}
 
-	// Crear archivo
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error creando archivo: %v", err)
+// getLinksEpisodes obtiene la lista de episodios de un anime y su ficha, que está en la misma página.
+// Con verbose en false no se muestra el progreso.
+func getLinksEpisodes(animeName, animeLink string, verbose bool) (*AnimeDetails, []Episode, error) {
+	if verbose {
+		fmt.Printf("Procesando: %s, %s\n\n", animeName, animeLink)
 	}
-	defer file.Close()
 
-	// Escribir encabezado
-	fmt.Fprintf(file, "ENLACES DE DESCARGA - %s\n", animeName)
-	fmt.Fprintf(file, "Generado el: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Fprintf(file, "========================================\n\n")
-
-	// Escribir enlaces por episodio
-	for _, episode := range episodes {
-		downloads, exists := allDownloads[episode.Link]
-		if !exists || len(downloads) == 0 {
-			continue
-		}
+	var details *AnimeDetails
+	var episodesList []Episode
 
-		fmt.Fprintf(file, "EPISODIO: %s\n", episode.Name)
-		fmt.Fprintf(file, "----------------------------------------\n")
+	err := pageFetcher.fetch(urlBase+animeLink, func(doc *goquery.Document) bool {
+		details = selectors.animeDetails(doc, animeLink)
+		episodesList = selectors.episodes(doc)
+		return len(episodesList) > 0
+	})
+	// Un anime con ficha pero sin episodios (ej. próximamente) es válido
+	if errors.Is(err, errNoContent) && details != nil && details.Title != "" {
+		err = nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-		for _, download := range downloads {
-			fmt.Fprintf(file, "Proveedor: %s\n", download.ProviderName)
-			fmt.Fprintf(file, "Enlace: %s\n\n", download.DownloadURL)
+	if verbose {
+		if len(episodesList) == 0 {
+			fmt.Println("Episodios no encontrados.")
+		} else {
+			fmt.Printf("Total de episodios disponibles: %d\n\n", len(episodesList))
 		}
-
-		fmt.Fprintf(file, "\n")
 	}
 
-	ProcessFileToMetalink(filename, filename+".metalink")
-
-	return nil
+	return details, episodesList, nil
 }
 
-// getLinksEpisodes obtiene la lista de episodios de un anime
-func getLinksEpisodes(animeName, animeLink string) ([]Episode, error) {
-	fmt.Printf("Procesando: %s, %s\n\n", animeName, animeLink)
+// fetchEpisodeDownloads obtiene, desenvuelve y resuelve los enlaces de un episodio
+func fetchEpisodeDownloads(episode Episode) ([]Download, error) {
+	downloadList, err := getDownloadLinksEpisode(episode.Link)
+	if err != nil {
+		return nil, err
+	}
 
-	var episodesList []Episode
+	// Desenvolver acortadores y resolver enlaces directos de proveedores conocidos
+	unwrapDownloads(downloadList)
+	resolveDownloads(downloadList)
 
-	// Configurar chromedp con opciones mejoradas
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-javascript", false), // Habilitamos JS
-		chromedp.Flag("disable-web-security", true),
-		chromedp.Flag("disable-features", "VizDisplayCompositor"),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("disable-images", true),
-	)
-
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
-
-	// Configurar contexto sin logging para evitar errores de cookies
-	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(func(string, ...interface{}) {}))
-	defer cancel()
-
-	ctx, cancel = context.WithTimeout(ctx, 25*time.Second)
-	defer cancel()
-
-	var htmlContent string
-	err := chromedp.Run(ctx,
-		chromedp.Navigate(urlBase+animeLink),
-		chromedp.Sleep(3*time.Second), // Esperar más tiempo para carga completa
-		chromedp.OuterHTML("html", &htmlContent),
-	)
+	return downloadList, nil
+}
 
-	if err != nil {
-		// Si ChromeDP falla, intentar con HTTP simple
-		return getEpisodesWithHTTP(animeLink)
+// printEpisodeResult muestra la cantidad de enlaces encontrados para un episodio
+func printEpisodeResult(downloadList []Download) {
+	if len(downloadList) > 0 {
+		fmt.Printf(" ✅ %d enlaces encontrados\n", len(downloadList))
+	} else {
+		fmt.Printf(" ⚠️  Sin enlaces\n")
 	}
+}
 
-	// Parsear HTML
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil, fmt.Errorf("error parseando HTML: %v", err)
+// fetchAllDownloads obtiene los enlaces de todos los episodios.
+// Al final reintenta una vez los episodios que fallaron por errores transitorios.
+// Con verbose en false no se muestra el progreso por episodio (ej. procesos en paralelo).
+func fetchAllDownloads(episodesList []Episode, verbose bool) map[string][]Download {
+	// Mapa para almacenar todos los downloads
+	allDownloads := make(map[string][]Download)
+
+	if verbose {
+		fmt.Println("Obteniendo enlaces de descarga de todos los episodios...")
 	}
 
-	// Encontrar episodios
-	doc.Find("ul.ListCaps li").Each(func(i int, s *goquery.Selection) {
-		episodeLink := s.Find("a")
-		episodeName := episodeLink.Find("p").Text()
-		href, exists := episodeLink.Attr("href")
+	// Episodios con errores transitorios para el pase final de reintentos
+	var failedEpisodes []Episode
 
-		if exists && episodeName != "" {
-			episodesList = append(episodesList, Episode{
-				Name: strings.TrimSpace(episodeName),
-				Link: href,
-			})
+	// Obtener enlaces de cada episodio
+	for i, episode := range episodesList {
+		if verbose {
+			fmt.Printf("Procesando episodio %d/%d: %s", i+1, len(episodesList), episodeLabel(episode))
 		}
-	})
-
-	if len(episodesList) == 0 {
-		fmt.Println("Episodios no encontrados con ChromeDP, intentando con HTTP...")
-		return getEpisodesWithHTTP(animeLink)
-	}
 
-	fmt.Printf("Total de episodios disponibles: %d\n\n", len(episodesList))
-	return episodesList, nil
-}
-
-// getEpisodesWithHTTP obtiene episodios usando solo HTTP (fallback)
-func getEpisodesWithHTTP(animeLink string) ([]Episode, error) {
-	var episodesList []Episode
+		downloadList, err := fetchEpisodeDownloads(episode)
+		if err != nil {
+			if verbose {
+				fmt.Printf(" ❌ Error: %v\n", err)
+			}
+			if isRetryable(err) {
+				failedEpisodes = append(failedEpisodes, episode)
+			}
+			continue
+		}
 
-	client := &http.Client{
-		Timeout: 15 * time.Second,
+		allDownloads[episode.Link] = downloadList
+		if verbose {
+			printEpisodeResult(downloadList)
+		}
 	}
 
-	req, err := http.NewRequest("GET", urlBase+animeLink, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creando request: %v", err)
-	}
+	// Reintentar una última vez los episodios que fallaron por errores transitorios
+	if len(failedEpisodes) > 0 {
+		if verbose {
+			fmt.Printf("\n🔁 Reintentando %d episodios fallidos...\n", len(failedEpisodes))
+		}
 
-	// Headers para simular navegador
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		for i, episode := range failedEpisodes {
+			if verbose {
+				fmt.Printf("Reintentando episodio %d/%d: %s", i+1, len(failedEpisodes), episodeLabel(episode))
+			}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error haciendo request HTTP: %v", err)
-	}
-	defer resp.Body.Close()
+			downloadList, err := fetchEpisodeDownloads(episode)
+			if err != nil {
+				if verbose {
+					fmt.Printf(" ❌ Error: %v\n", err)
+				}
+				continue
+			}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("error HTTP: código de estado %d", resp.StatusCode)
+			allDownloads[episode.Link] = downloadList
+			if verbose {
+				printEpisodeResult(downloadList)
+			}
+		}
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error parseando HTML con HTTP: %v", err)
+	return allDownloads
+}
+
+// episodeLabel nombre del episodio, con el anime delante si pertenece a otra entrada (franquicia)
+func episodeLabel(episode Episode) string {
+	if episode.Anime == "" {
+		return episode.Name
 	}
 
-	// Buscar episodios
-	doc.Find("ul.ListCaps li").Each(func(i int, s *goquery.Selection) {
-		episodeLink := s.Find("a")
-		episodeName := episodeLink.Find("p").Text()
-		href, exists := episodeLink.Attr("href")
+	return fmt.Sprintf("%s - %s", episode.Anime, episode.Name)
+}
 
-		if exists && episodeName != "" {
-			episodesList = append(episodesList, Episode{
-				Name: strings.TrimSpace(episodeName),
-				Link: href,
-			})
-		}
-	})
+// processOptions opciones del procesamiento de un anime
+type processOptions struct {
+	DownloadDir  string // si no está vacío, descarga los episodios con enlaces directos
+	OutputDir    string // directorio de las exportaciones (vacío = directorio actual)
+	Franchise    bool   // recorrer también las entradas relacionadas (temporadas, OVAs, películas)
+	FranchiseMax int
+	Episodes     episodeRanges // solo estos episodios (vacío = todos)
+	Quiet        bool          // sin progreso por episodio
+}
 
-	if len(episodesList) == 0 {
-		fmt.Println("Episodios no encontrados.")
-	} else {
-		fmt.Printf("Total de episodios disponibles: %d\n\n", len(episodesList))
-	}
+// processResult resumen del procesamiento de un anime
+type processResult struct {
+	File      string `json:"file,omitempty"` // archivo de texto generado
+	Franchise int    `json:"franchise,omitempty"`
+	Episodes  int    `json:"episodes"`
+	Processed int    `json:"processed"`
+	Links     int    `json:"links"`
 
-	return episodesList, nil
+	episodesList []Episode
+	allDownloads map[string][]Download
 }
 
 // processAnimes procesa la lista de animes y permite al usuario seleccionar uno
-func processAnimes(animesList []Anime) error {
+func processAnimes(animesList []Anime, options processOptions) error {
 	fmt.Println("Lista de animes disponibles:")
 
 	for i, anime := range animesList {
-		fmt.Printf("%d.- Anime: %s, enlace: %s\n", i+1, anime.Name, anime.Link)
+		fmt.Printf("%d.- Anime: %s, enlace: %s\n", i+1, animeLabel(anime), anime.Link)
 	}
 
 	fmt.Print("\nSelecciona un número para generar archivo con enlaces de descarga: ")
@@ -418,96 +337,204 @@ func processAnimes(animesList []Anime) error {
 
 	selectedAnime := animesList[option-1]
 	fmt.Printf("Seleccionado: %s, %s\n", selectedAnime.Name, selectedAnime.Link)
-	fmt.Println("\nProcesando episodios...")
 
-	episodesList, err := getLinksEpisodes(selectedAnime.Name, selectedAnime.Link)
+	result, err := processAnime(selectedAnime, options)
 	if err != nil {
-		return fmt.Errorf("error obteniendo episodios: %v", err)
+		return err
 	}
 
-	if len(episodesList) == 0 {
-		return fmt.Errorf("no se encontraron episodios para este anime")
+	absPath, _ := filepath.Abs(result.File)
+
+	fmt.Printf("\n✅ ¡Proceso completado!\n")
+	fmt.Printf("📁 Archivo generado: %s\n", result.File)
+	fmt.Printf("📍 Ubicación completa: %s\n", absPath)
+
+	// Mostrar estadísticas
+	fmt.Printf("\n📊 Estadísticas:\n")
+	if result.Franchise > 0 {
+		fmt.Printf("   • Entradas de la franquicia: %d\n", result.Franchise)
 	}
+	fmt.Printf("   • Total de episodios: %d\n", result.Episodes)
+	fmt.Printf("   • Episodios procesados: %d\n", result.Processed)
+	fmt.Printf("   • Total de enlaces: %d\n", result.Links)
 
-	// Mapa para almacenar todos los downloads
-	allDownloads := make(map[string][]Download)
+	if options.DownloadDir != "" {
+		downloadEpisodes(selectedAnime.Name, result.episodesList, result.allDownloads, options.DownloadDir, !options.Quiet)
+	}
+
+	return nil
+}
 
-	fmt.Println("Obteniendo enlaces de descarga de todos los episodios...")
+// processAnime obtiene los episodios y enlaces del anime (o de toda su franquicia) y escribe las exportaciones
+func processAnime(anime Anime, options processOptions) (processResult, error) {
+	var result processResult
+	var details *AnimeDetails
+	var franchise []*AnimeDetails
+	var episodesList []Episode
+	var err error
 
-	// Obtener enlaces de cada episodio
-	for i, episode := range episodesList {
-		fmt.Printf("Procesando episodio %d/%d: %s", i+1, len(episodesList), episode.Name)
+	verbose := !options.Quiet
+
+	if options.Franchise {
+		if verbose {
+			fmt.Println("\nRecorriendo la franquicia...")
+		}
 
-		downloadList, err := getDownloadLinksEpisode(episode.Link)
+		franchise, err = collectFranchise(anime.Link, options.FranchiseMax, verbose)
 		if err != nil {
-			fmt.Printf(" ❌ Error: %v\n", err)
-			continue
+			return result, fmt.Errorf("error obteniendo la franquicia: %w", err)
+		}
+		if verbose {
+			fmt.Printf("\n📚 %d entradas en la franquicia\n\n", len(franchise))
 		}
 
-		allDownloads[episode.Link] = downloadList
+		for _, entry := range franchise {
+			if entry.Link == anime.Link {
+				details = entry
+			}
+		}
 
-		if len(downloadList) > 0 {
-			fmt.Printf(" ✅ %d enlaces encontrados\n", len(downloadList))
-		} else {
-			fmt.Printf(" ⚠️  Sin enlaces\n")
+		if verbose {
+			fmt.Println("Procesando episodios...")
+		}
+		episodesList = getFranchiseEpisodes(franchise, verbose)
+	} else {
+		if verbose {
+			fmt.Println("\nProcesando episodios...")
 		}
 
-		// Pausa más corta entre requests
-		time.Sleep(500 * time.Millisecond)
+		details, episodesList, err = getLinksEpisodes(anime.Name, anime.Link, verbose)
+		if err != nil {
+			return result, fmt.Errorf("error obteniendo episodios: %w", err)
+		}
 	}
 
-	// Escribir todos los enlaces al archivo
-	err = writeDownloadsToFile(selectedAnime.Name, episodesList, allDownloads)
-	if err != nil {
-		return fmt.Errorf("error escribiendo archivo: %v", err)
+	if len(options.Episodes) > 0 {
+		episodesList = options.Episodes.filter(episodesList)
 	}
 
-	filename := sanitizeFilename(selectedAnime.Name) + ".txt"
-	absPath, _ := filepath.Abs(filename)
+	if len(episodesList) == 0 {
+		return result, fmt.Errorf("no se encontraron episodios para este anime")
+	}
 
-	fmt.Printf("\n✅ ¡Proceso completado!\n")
-	fmt.Printf("📁 Archivo generado: %s\n", filename)
-	fmt.Printf("📍 Ubicación completa: %s\n", absPath)
+	allDownloads := fetchAllDownloads(episodesList, verbose)
 
-	// Mostrar estadísticas
-	totalEpisodes := len(episodesList)
-	totalLinks := 0
-	processedEpisodes := 0
+	// Escribir todos los enlaces al archivo
+	result.File, err = writeDownloadsToFile(options.OutputDir, anime.Name, details, franchise, episodesList, allDownloads)
+	if err != nil {
+		return result, fmt.Errorf("error escribiendo archivo: %v", err)
+	}
 
+	result.Franchise = len(franchise)
+	result.Episodes = len(episodesList)
 	for _, downloads := range allDownloads {
 		if len(downloads) > 0 {
-			processedEpisodes++
-			totalLinks += len(downloads)
+			result.Processed++
+			result.Links += len(downloads)
 		}
 	}
+	result.episodesList = episodesList
+	result.allDownloads = allDownloads
 
-	fmt.Printf("\n📊 Estadísticas:\n")
-	fmt.Printf("   • Total de episodios: %d\n", totalEpisodes)
-	fmt.Printf("   • Episodios procesados: %d\n", processedEpisodes)
-	fmt.Printf("   • Total de enlaces: %d\n", totalLinks)
+	return result, nil
+}
 
-	return nil
+// commands subcomandos disponibles además de la búsqueda por nombre
+var commands = map[string]func(args []string) error{
+	"check":     runCheck,
+	"airing":    runAiring,
+	"batch":     runBatch,
+	"info":      runInfo,
+	"latest":    runLatest,
+	"notify":    runNotify,
+	"selectors": runSelectors,
+	"serve":     runServe,
+	"sync":      runSync,
+	"watchlist": runWatchlist,
 }
 
 func main() {
+	// Despachar subcomandos (ej. `check archivo.txt`)
+	if len(os.Args) > 1 {
+		if command, exists := commands[os.Args[1]]; exists {
+			if err := command(os.Args[2:]); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			return
+		}
+	}
+
 	// Definir argumentos de línea de comandos
 	search := flag.String("search", "", "Nombre del anime a buscar")
 	searchShort := flag.String("s", "", "Nombre del anime a buscar (versión corta)")
+	downloadDir := flag.String("download", "", "Directorio donde descargar los episodios con enlace directo")
+	franchise := flag.Bool("franchise", false, "Incluir las temporadas, OVAs y películas relacionadas en un solo archivo")
+	franchiseMax := flag.Int("franchise-max", defaultFranchiseMax, "Entradas de la franquicia a recorrer como máximo (0 = todas)")
+	maxPages := flag.Int("max-pages", defaultMaxSearchPages, "Páginas de resultados de búsqueda a recorrer como máximo (0 = todas)")
+	var genres, years, types, statuses listFlag
+	flag.Var(&genres, "genre", "Filtrar por género (ej. accion,comedia; ver --list-genres)")
+	flag.Var(&years, "year", "Filtrar por año de emisión (ej. 2024)")
+	flag.Var(&types, "type", "Filtrar por tipo: tv, movie, special, ova")
+	flag.Var(&statuses, "status", "Filtrar por estado: emision, finalizado, proximamente")
+	order := flag.String("order", "", "Orden del listado: default, updated, added, title, rating")
+	listGenres := flag.Bool("list-genres", false, "Mostrar los géneros disponibles para --genre")
+	selectorsFile := flag.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(flag.CommandLine)
 	flag.Parse()
 
+	if err := applyNetworkFlags(); err != nil {
+		log.Fatalf("Error en opciones de red: %v", err)
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		log.Fatalf("Error cargando selectores: %v", err)
+	}
+	selectors = profile
+
 	// Usar el valor del argumento si existe
 	searchTerm := *search
 	if searchTerm == "" {
 		searchTerm = *searchShort
 	}
 
-	if searchTerm == "" {
+	if *listGenres {
+		printGenres()
+		return
+	}
+
+	yearList, err := parseYears(years)
+	if err != nil {
+		log.Fatalf("Error en filtros: %v", err)
+	}
+
+	filter := BrowseFilter{
+		Query:    searchTerm,
+		Genres:   genres,
+		Years:    yearList,
+		Types:    types,
+		Statuses: statuses,
+		Order:    *order,
+	}
+
+	if filter.IsEmpty() {
 		fmt.Println("No se proporcionó término de búsqueda.")
 		fmt.Println("Uso: ./programa --search \"nombre del anime\" o ./programa -s \"nombre del anime\"")
+		fmt.Println("     ./programa --genre accion --year 2024 --type tv --status emision [--order rating]")
+		fmt.Println("     ./programa batch [--concurrency N] lista.txt")
+		fmt.Println("     ./programa info slug|URL")
+		fmt.Println("     ./programa latest [--links]")
+		fmt.Println("     ./programa airing")
+		fmt.Println("     ./programa watchlist add|remove|list slug...")
+		fmt.Println("     ./programa sync")
+		fmt.Println("     ./programa notify --webhook URL|--discord-webhook URL|--slack-webhook URL|--notify-command cmd")
+		fmt.Println("     ./programa serve [--interval 1h | --cron \"0 */6 * * *\"] [--jitter 5m]")
+		fmt.Println("     ./programa check archivo.txt|archivo.json|archivo.metalink")
+		fmt.Println("     ./programa selectors test [--selectors perfil.json] páginas.html|directorio...")
 		return
 	}
 
-	animesList, err := searchAnime(searchTerm)
+	animesList, err := browseAnime(filter, *maxPages)
 	if err != nil {
 		log.Fatalf("Error buscando anime: %v", err)
 	}
@@ -517,7 +544,15 @@ func main() {
 		return
 	}
 
-	if err := processAnimes(animesList); err != nil {
+	options := processOptions{
+		DownloadDir:  *downloadDir,
+		Franchise:    *franchise,
+		FranchiseMax: *franchiseMax,
+	}
+
+	if err := processAnimes(animesList, options); err != nil {
 		log.Fatalf("Error procesando animes: %v", err)
 	}
+
+	pageCache.printStats()
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// mediafireDirectRegex detecta enlaces directos de MediaFire dentro del HTML
+var mediafireDirectRegex = regexp.MustCompile(`https?://download\d*\.mediafire\.com/[^"'\s<>]+`)
+
+// mediafireDeadMarkers textos de la página de MediaFire cuando el archivo ya no existe
+var mediafireDeadMarkers = []string{
+	"file removed",
+	"file has been removed",
+	"invalid or deleted file",
+	"the key you provided for file download was invalid",
+}
+
+// mediafireSizeRegex extrae el tamaño del texto del botón de descarga
+var mediafireSizeRegex = regexp.MustCompile(`\(([\d.,]+\s*[KMG]?B)\)`)
+
+// resolveMediafire obtiene el enlace directo, nombre y tamaño de un archivo de MediaFire
+func resolveMediafire(pageURL string) (*Resolved, error) {
+	body, finalURL, err := fetchProviderPage(pageURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// MediaFire redirige a error.php cuando el archivo fue eliminado
+	if strings.Contains(finalURL.Path, "error.php") {
+		return nil, fmt.Errorf("archivo no disponible en MediaFire")
+	}
+
+	return parseMediafirePage(body)
+}
+
+// parseMediafirePage extrae el enlace directo desde el HTML de la página de MediaFire
+func parseMediafirePage(html string) (*Resolved, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("error parseando HTML: %v", err)
+	}
+
+	button := doc.Find("a#downloadButton").First()
+	directURL := ""
+
+	// Archivo eliminado: la página se sirve con código 200 pero sin botón de descarga
+	if button.Length() == 0 {
+		text := strings.ToLower(doc.Text())
+		for _, marker := range mediafireDeadMarkers {
+			if strings.Contains(text, marker) {
+				return nil, fmt.Errorf("archivo no disponible en MediaFire")
+			}
+		}
+	}
+
+	// Variante clásica: el enlace directo está en el href del botón
+	if href, exists := button.Attr("href"); exists && mediafireDirectRegex.MatchString(href) {
+		directURL = href
+	}
+
+	// Variante nueva: el enlace viene codificado en base64 en data-scrambled-url.
+	// Si lo decodificado no es un enlace directo se sigue con la búsqueda en el HTML.
+	if directURL == "" {
+		if scrambled, exists := button.Attr("data-scrambled-url"); exists {
+			if decoded, err := base64.StdEncoding.DecodeString(scrambled); err == nil && isMediafireDirect(string(decoded)) {
+				directURL = string(decoded)
+			}
+		}
+	}
+
+	// Último recurso: buscar el enlace directo en cualquier parte del HTML
+	if directURL == "" {
+		directURL = mediafireDirectRegex.FindString(html)
+	}
+
+	if directURL == "" {
+		return nil, fmt.Errorf("enlace directo de MediaFire no encontrado")
+	}
+
+	resolved := &Resolved{
+		URL:      directURL,
+		FileName: mediafireFileName(doc, directURL),
+	}
+
+	// Tamaño desde la lista de detalles o desde el texto del botón
+	doc.Find(".details li").Each(func(i int, s *goquery.Selection) {
+		if strings.Contains(strings.ToLower(s.Text()), "size") {
+			resolved.Size = parseSize(s.Find("span").Text())
+		}
+	})
+
+	if resolved.Size == 0 {
+		if matches := mediafireSizeRegex.FindStringSubmatch(button.Text()); len(matches) > 1 {
+			resolved.Size = parseSize(matches[1])
+		}
+	}
+
+	return resolved, nil
+}
+
+// isMediafireDirect indica si el texto completo es un enlace directo de MediaFire
+func isMediafireDirect(text string) bool {
+	return text != "" && mediafireDirectRegex.FindString(text) == text
+}
+
+// mediafireFileName obtiene el nombre del archivo desde la página o desde el enlace directo
+func mediafireFileName(doc *goquery.Document, directURL string) string {
+	if title, exists := doc.Find(".dl-btn-label").Attr("title"); exists && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+
+	if name := strings.TrimSpace(doc.Find(".filename").First().Text()); name != "" {
+		return name
+	}
+
+	name := path.Base(directURL)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	return name
+}
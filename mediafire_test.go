@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadFixture lee un archivo de testdata
+func loadFixture(t *testing.T, parts ...string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(append([]string{"testdata"}, parts...)...))
+	if err != nil {
+		t.Fatalf("error leyendo fixture: %v", err)
+	}
+
+	return string(content)
+}
+
+// megabytes convierte MB a bytes con la misma aritmética que parseSize
+func megabytes(value float64) int64 {
+	return int64(value * (1 << 20))
+}
+
+func TestParseMediafirePage(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		url      string
+		fileName string
+		size     int64
+		wantErr  bool
+	}{
+		{
+			fixture:  "direct.html",
+			url:      "https://download1588.mediafire.com/abc123/xyz/Naruto_01.mp4",
+			fileName: "Naruto_01.mp4",
+			size:     megabytes(245.3),
+		},
+		{
+			fixture:  "scrambled.html",
+			url:      "https://download2291.mediafire.com/q9w8e7/r6t5y4/One%20Piece%201000.mp4",
+			fileName: "One Piece 1000.mp4",
+			size:     megabytes(1234.5),
+		},
+		{
+			fixture:  "scrambled-garbage.html",
+			url:      "https://download1234.mediafire.com/k1l2m3/n4o5p6/Bleach_366.mp4",
+			fileName: "Bleach_366.mp4",
+			size:     megabytes(310),
+		},
+		{
+			fixture: "dead.html",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			resolved, err := parseMediafirePage(loadFixture(t, "mediafire", tt.fixture))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba error, se obtuvo %+v", resolved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			if resolved.URL != tt.url {
+				t.Errorf("URL = %q, se esperaba %q", resolved.URL, tt.url)
+			}
+			if resolved.FileName != tt.fileName {
+				t.Errorf("FileName = %q, se esperaba %q", resolved.FileName, tt.fileName)
+			}
+			if resolved.Size != tt.size {
+				t.Errorf("Size = %d, se esperaba %d", resolved.Size, tt.size)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"245.3MB", megabytes(245.3)},
+		{"245,3 MB", megabytes(245.3)},
+		{"1,234.5 MB", megabytes(1234.5)},
+		{"1.5 GB", megabytes(1.5 * 1024)},
+		{"512KB", 512 << 10},
+		{"100 B", 100},
+		{"2gb", 2 << 30},
+		{"", 0},
+		{"abc MB", 0},
+		{"12", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseSize(tt.text); got != tt.want {
+			t.Errorf("parseSize(%q) = %d, se esperaba %d", tt.text, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -39,7 +40,7 @@ type File struct {
 	XMLName     xml.Name `xml:"file"`
 	Name        string   `xml:"name,attr"`
 	Description string   `xml:"description"`
-	Size        int64    `xml:"size"`
+	Size        int64    `xml:"size,omitempty"`
 	URLs        []URL    `xml:"url"`
 	Hashes      []Hash   `xml:"hash,omitempty"`
 }
@@ -70,6 +71,7 @@ type MetalinkFile struct {
 	Name        string
 	Description string
 	URL         string
+	Mirrors     []string
 	Size        int64
 	Extension   string
 }
@@ -130,6 +132,16 @@ func (mg *MetalinkGenerator) GenerateMetalink() (string, error) {
 				},
 			},
 		}
+
+		// Los mirrors se añaden con preferencia decreciente
+		for i, mirror := range file.Mirrors {
+			xmlFile.URLs = append(xmlFile.URLs, URL{
+				Location:   "cloud",
+				Preference: max(100-10*(i+1), 1),
+				Value:      mirror,
+			})
+		}
+
 		metalink.Files = append(metalink.Files, xmlFile)
 	}
 
@@ -231,6 +243,93 @@ func ProcessFileToMetalink(inputFile, outputFile string) error {
 	return mg.SaveToFile(outputFile)
 }
 
+// episodeNumberRegex extrae el número de episodio del nombre
+var episodeNumberRegex = regexp.MustCompile(`(\d+)\s*$`)
+
+// CreateMetalinkFromDownloads crea un metalink a partir de los enlaces obtenidos en memoria.
+// Los enlaces directos resueltos tienen prioridad sobre los enlaces MEGA; el tamaño solo se
+// indica cuando se conoce el del enlace principal.
+func CreateMetalinkFromDownloads(title, baseName string, episodes []Episode, allDownloads map[string][]Download) (*MetalinkGenerator, error) {
+	mg := NewMetalinkGenerator(title, "Serie completa de anime", "Anime")
+
+	for i, episode := range episodes {
+		episodeNum := i + 1
+		if matches := episodeNumberRegex.FindStringSubmatch(episode.Name); len(matches) > 1 {
+			if num, err := strconv.Atoi(matches[1]); err == nil {
+				episodeNum = num
+			}
+		}
+
+		// En una franquicia cada entrada lleva su propio nombre para no repetir archivos
+		episodeTitle, episodeBase := title, baseName
+		if episode.Anime != "" {
+			episodeTitle, episodeBase = episode.Anime, sanitizeFilename(episode.Anime)
+		}
+
+		file := MetalinkFile{
+			Name:        fmt.Sprintf("%s_Episodio_%02d.mkv", episodeBase, episodeNum),
+			Description: fmt.Sprintf("%s - Episodio %d", episodeTitle, episodeNum),
+			Extension:   "mkv",
+		}
+
+		// El primer enlace directo es la fuente principal. Cada proveedor sube su propia
+		// codificación: otro directo solo es mirror si es el mismo archivo (mismo nombre y tamaño).
+		var primary *Resolved
+		for _, download := range allDownloads[episode.Link] {
+			direct := download.Direct
+			// Un playlist HLS no es el archivo (se bajaría solo el .m3u8) y el metalink no puede
+			// llevar las cabeceras (Referer) que piden Streamtape o YourUpload
+			if direct == nil || isHLS(direct.URL) || len(direct.Headers) > 0 {
+				continue
+			}
+
+			if primary == nil {
+				primary = direct
+				file.URL = direct.URL
+				if direct.FileName != "" {
+					file.Name = direct.FileName
+					file.Extension = strings.TrimPrefix(filepath.Ext(file.Name), ".")
+				}
+				file.Size = direct.Size
+				continue
+			}
+
+			if sameResolvedFile(primary, direct) {
+				file.Mirrors = append(file.Mirrors, direct.URL)
+			}
+		}
+
+		// Sin enlace directo queda la página de MEGA para los gestores que la soportan,
+		// sin tamaño porque no es el archivo
+		if primary == nil {
+			for _, download := range allDownloads[episode.Link] {
+				if strings.Contains(download.DownloadURL, "mega.nz") {
+					file.URL = download.DownloadURL
+					file.Size = 0
+					break
+				}
+			}
+		}
+
+		if file.URL == "" {
+			continue
+		}
+
+		mg.Files = append(mg.Files, file)
+	}
+
+	if len(mg.Files) == 0 {
+		return nil, fmt.Errorf("no se encontraron episodios con enlaces directos o MEGA")
+	}
+
+	return mg, nil
+}
+
+// sameResolvedFile indica si dos enlaces directos sirven el mismo archivo (mismo nombre y tamaño conocido)
+func sameResolvedFile(a, b *Resolved) bool {
+	return a.FileName != "" && a.FileName == b.FileName && a.Size > 0 && a.Size == b.Size
+}
+
 // Función utilitaria para validar URLs MEGA
 func ValidateMegaURL(url string) bool {
 	megaRegex := regexp.MustCompile(`^https://mega\.nz/#![A-Za-z0-9_-]+![A-Za-z0-9_-]+$`)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCreateMetalinkFromDownloads(t *testing.T) {
+	episode := Episode{Name: "Episodio 1", Link: "/ver/test-anime-1"}
+	mega := Download{ProviderName: "MEGA", DownloadURL: "https://mega.nz/file/AbCdEf#key"}
+
+	tests := []struct {
+		name      string
+		downloads []Download
+		url       string
+		mirrors   []string
+		size      int64
+	}{
+		{
+			name: "mirror del mismo archivo",
+			downloads: []Download{
+				{ProviderName: "MediaFire", Direct: &Resolved{URL: "https://download1.mediafire.com/a/ep1.mp4", FileName: "ep1.mp4", Size: 1000}},
+				{ProviderName: "Otro", Direct: &Resolved{URL: "https://cdn.example.com/ep1.mp4", FileName: "ep1.mp4", Size: 1000}},
+				mega,
+			},
+			url:     "https://download1.mediafire.com/a/ep1.mp4",
+			mirrors: []string{"https://cdn.example.com/ep1.mp4"},
+			size:    1000,
+		},
+		{
+			name: "otra codificación no es mirror",
+			downloads: []Download{
+				{ProviderName: "MediaFire", Direct: &Resolved{URL: "https://download1.mediafire.com/a/ep1.mp4", FileName: "ep1.mp4", Size: 1000}},
+				{ProviderName: "Otro", Direct: &Resolved{URL: "https://cdn.example.com/ep1-720.mp4", FileName: "ep1-720.mp4", Size: 600}},
+			},
+			url:  "https://download1.mediafire.com/a/ep1.mp4",
+			size: 1000,
+		},
+		{
+			name: "directo con cabeceras se omite",
+			downloads: []Download{
+				{ProviderName: "Stape", Direct: &Resolved{URL: "https://streamtape.com/get_video?id=x", Headers: map[string]string{"Referer": "https://streamtape.com/"}}},
+				mega,
+			},
+			url: "https://mega.nz/file/AbCdEf#key",
+		},
+		{
+			name:      "solo MEGA sin tamaño",
+			downloads: []Download{mega},
+			url:       "https://mega.nz/file/AbCdEf#key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mg, err := CreateMetalinkFromDownloads("Test Anime", "Test_Anime", []Episode{episode}, map[string][]Download{episode.Link: tt.downloads})
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if len(mg.Files) != 1 {
+				t.Fatalf("%d archivos; se esperaba 1", len(mg.Files))
+			}
+
+			file := mg.Files[0]
+			if file.URL != tt.url {
+				t.Errorf("URL = %q, se esperaba %q", file.URL, tt.url)
+			}
+			if !slices.Equal(file.Mirrors, tt.mirrors) {
+				t.Errorf("Mirrors = %v, se esperaba %v", file.Mirrors, tt.mirrors)
+			}
+			if file.Size != tt.size {
+				t.Errorf("Size = %d, se esperaba %d", file.Size, tt.size)
+			}
+		})
+	}
+}
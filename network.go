@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// userAgent identificación de navegador usada en todas las peticiones
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// browserHeaders headers que simulan un navegador real
+var browserHeaders = map[string]string{
+	"User-Agent":      userAgent,
+	"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+	"Accept-Language": "es-ES,es;q=0.8,en-US;q=0.5,en;q=0.3",
+	"Accept-Encoding": "gzip, deflate, br",
+}
+
+// networkConfig opciones de red compartidas por todos los comandos
+type networkConfig struct {
+	Rate          float64
+	Burst         int
+	RateHosts     string
+	Proxy         string
+	Cookies       string
+	ChromeProfile string
+	CacheDir      string
+	CacheTTL      time.Duration
+	NoCache       bool
+	Refresh       bool
+	Record        string
+	Replay        string
+	Fetch         string
+}
+
+// network configuración de red de la ejecución actual
+var network networkConfig
+
+// rateLimiter limitador de peticiones compartido por HTTP y Chrome
+var rateLimiter = newHostRateLimiter(2, 1, parseRateHosts(defaultRateHosts))
+
+// httpTransport transporte compartido por todos los clientes HTTP
+var httpTransport http.RoundTripper = &decompressingTransport{
+	base: http.DefaultTransport.(*http.Transport).Clone(),
+}
+
+// registerNetworkFlags registra las opciones de red en el conjunto de flags del comando
+func registerNetworkFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&network.Rate, "rate", 2, "Peticiones por segundo por host (0 desactiva el límite)")
+	fs.IntVar(&network.Burst, "burst", 1, "Peticiones seguidas permitidas por host antes de limitar")
+	fs.StringVar(&network.RateHosts, "rate-hosts", defaultRateHosts, "Hosts a los que se aplica --rate, separados por comas (animeflv, all o dominios)")
+	fs.StringVar(&network.Proxy, "proxy", "", "Proxy para HTTP y Chrome (http://, https:// o socks5://, admite usuario:clave@)")
+	fs.StringVar(&network.Cookies, "cookies", "", "Archivo cookies.txt (formato Netscape) donde cargar y guardar las cookies entre ejecuciones")
+	fs.StringVar(&network.ChromeProfile, "chrome-profile", "", "Directorio de perfil persistente de Chrome (user-data-dir)")
+	fs.StringVar(&network.CacheDir, "cache-dir", defaultCacheDir(), "Directorio de la caché de páginas")
+	fs.DurationVar(&network.CacheTTL, "cache-ttl", defaultCacheTTL, "Tiempo de vigencia de las páginas en caché (ej. 30m, 12h)")
+	fs.BoolVar(&network.NoCache, "no-cache", false, "No leer ni guardar páginas en la caché")
+	fs.BoolVar(&network.Refresh, "refresh", false, "Ignorar la caché y volver a descargar las páginas (se actualiza la caché)")
+	fs.StringVar(&network.Record, "record", "", "Grabar en el directorio todas las páginas obtenidas (URL, headers y contenido)")
+	fs.StringVar(&network.Replay, "replay", "", "Servir todas las páginas desde un directorio grabado con --record, sin acceder a la red")
+	fs.StringVar(&network.Fetch, "fetch", defaultFetchStrategy, "Estrategias para obtener las páginas de AnimeFLV, en orden (http, chrome)")
+}
+
+// applyNetworkFlags aplica las opciones de red una vez parseados los flags
+func applyNetworkFlags() error {
+	rateLimiter = newHostRateLimiter(network.Rate, network.Burst, parseRateHosts(network.RateHosts))
+
+	proxyFunc, err := buildProxyFunc(network.Proxy)
+	if err != nil {
+		return err
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = proxyFunc
+	httpTransport = &decompressingTransport{base: base}
+
+	// Chrome no puede autenticarse en un proxy SOCKS5: avisar antes de que falle cada página
+	if proxyURL, err := activeProxy(urlBase); err == nil && isSocksWithAuth(proxyURL) {
+		fmt.Fprintln(os.Stderr, "⚠️  Chrome no admite proxies SOCKS5 con usuario y clave: las páginas y desafíos que requieran Chrome fallarán (usa un proxy HTTP o --fetch http)")
+	}
+
+	pageCache = newHTMLCache(network.CacheDir, network.CacheTTL, network.Refresh)
+	if network.NoCache {
+		pageCache = newHTMLCache("", 0, false)
+	}
+
+	if err := applyRecordFlags(); err != nil {
+		return err
+	}
+
+	// La cadena se arma al final porque depende de la caché y del modo replay
+	pageFetcher, err = newFetchChain(network.Fetch)
+	if err != nil {
+		return err
+	}
+
+	if network.Cookies != "" {
+		if err := sessionJar.Load(network.Cookies); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyRecordFlags activa la grabación o la reproducción de páginas
+func applyRecordFlags() error {
+	if network.Record != "" && network.Replay != "" {
+		return fmt.Errorf("--record y --replay no se pueden usar a la vez")
+	}
+
+	switch {
+	case network.Replay != "":
+		replay, err := newPageRecorder(network.Replay, true)
+		if err != nil {
+			return err
+		}
+		recorder = replay
+
+		// Sin red no hace falta limitar peticiones y la caché no debe alterar lo grabado
+		httpTransport = &replayTransport{recorder: recorder}
+		rateLimiter = newHostRateLimiter(0, 1, nil)
+		pageCache = newHTMLCache("", 0, false)
+
+	case network.Record != "":
+		record, err := newPageRecorder(network.Record, false)
+		if err != nil {
+			return err
+		}
+		recorder = record
+
+		// Las páginas leídas de la caché no pasarían por el grabador
+		httpTransport = &recordingTransport{base: httpTransport, recorder: recorder}
+		pageCache.refresh = true
+	}
+
+	return nil
+}
+
+// proxyConfig arma la configuración de proxy compartida por HTTP y Chrome.
+// Sin --proxy se usan HTTP_PROXY/HTTPS_PROXY/NO_PROXY, con ALL_PROXY si no hay uno específico;
+// con --proxy se sigue respetando NO_PROXY.
+func proxyConfig(proxy string) (*httpproxy.Config, error) {
+	if proxy != "" {
+		proxyURL, err := parseProxyURL(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		return &httpproxy.Config{
+			HTTPProxy:  socks5Scheme(proxyURL.String()),
+			HTTPSProxy: socks5Scheme(proxyURL.String()),
+			NoProxy:    getEnvAny("NO_PROXY", "no_proxy"),
+		}, nil
+	}
+
+	config := httpproxy.FromEnvironment()
+	if all := getEnvAny("ALL_PROXY", "all_proxy"); all != "" {
+		if config.HTTPProxy == "" {
+			config.HTTPProxy = all
+		}
+		if config.HTTPSProxy == "" {
+			config.HTTPSProxy = all
+		}
+	}
+	config.HTTPProxy = socks5Scheme(config.HTTPProxy)
+	config.HTTPSProxy = socks5Scheme(config.HTTPSProxy)
+
+	return config, nil
+}
+
+// socks5Scheme cambia socks5h:// por socks5://: httpproxy no reconoce socks5h (lo tomaría como
+// un proxy HTTP) y el cliente SOCKS de Go ya resuelve los nombres en el proxy
+func socks5Scheme(proxy string) string {
+	if rest, ok := strings.CutPrefix(proxy, "socks5h://"); ok {
+		return "socks5://" + rest
+	}
+
+	return proxy
+}
+
+// buildProxyFunc arma la función de proxy del transporte a partir de proxyConfig
+func buildProxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	config, err := proxyConfig(proxy)
+	if err != nil {
+		return nil, err
+	}
+	proxyForURL := config.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}, nil
+}
+
+// parseProxyURL valida la URL del proxy y su esquema
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy inválido: %s", proxy)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return proxyURL, nil
+	default:
+		return nil, fmt.Errorf("esquema de proxy no soportado: %s (usar http, https o socks5)", proxyURL.Scheme)
+	}
+}
+
+// activeProxy devuelve el proxy que usaría el cliente HTTP para la página (nil si va directo).
+// Chrome recibe así el mismo proxy que HTTP, incluido ALL_PROXY y las exclusiones de NO_PROXY.
+func activeProxy(pageURL string) (*url.URL, error) {
+	config, err := proxyConfig(network.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyURL, err := config.ProxyFunc()(u)
+	if err != nil || proxyURL == nil {
+		return nil, err
+	}
+
+	return parseProxyURL(proxyURL.String())
+}
+
+// isSocksWithAuth indica si el proxy es SOCKS5 con usuario y clave, que Chrome no admite
+func isSocksWithAuth(proxyURL *url.URL) bool {
+	return proxyURL != nil && proxyURL.User != nil && strings.HasPrefix(proxyURL.Scheme, "socks5")
+}
+
+// getEnvAny devuelve el primer valor no vacío entre las variables de entorno indicadas
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// newHTTPClient crea un cliente HTTP con el transporte compartido (timeout 0 = sin límite)
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: httpTransport,
+		Jar:       sessionJar,
+	}
+}
+
+// newBrowserRequest crea una petición con los headers de navegador
+func newBrowserRequest(method, link string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, link, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request: %v", err)
+	}
+
+	for key, value := range browserHeaders {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// decompressingTransport descomprime respuestas gzip, deflate y brotli.
+// Al pedir Accept-Encoding a mano Go no descomprime solo, por eso se hace aquí.
+type decompressingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *decompressingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin cuerpo no hay nada que descomprimir (gzip.NewReader fallaría con EOF)
+	if req.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent ||
+		resp.StatusCode == http.StatusNotModified || resp.ContentLength == 0 {
+		return resp, nil
+	}
+
+	var reader io.Reader
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		reader, err = newDeflateReader(resp.Body)
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	default:
+		return resp, nil
+	}
+
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("error descomprimiendo respuesta: %v", err)
+	}
+
+	resp.Body = &decompressedBody{Reader: reader, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return resp, nil
+}
+
+// newDeflateReader acepta deflate con envoltorio zlib (estándar) o crudo (servidores antiguos)
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+// decompressedBody cierra el cuerpo original al cerrar el lector descomprimido
+type decompressedBody struct {
+	io.Reader
+	raw io.ReadCloser
+}
+
+func (b *decompressedBody) Close() error {
+	if closer, ok := b.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.raw.Close()
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// notifyTimeout tiempo máximo de cada notificación (petición o comando)
+const notifyTimeout = 30 * time.Second
+
+// notifyRetryPolicy política más corta que la de AnimeFLV: una notificación no debe demorar el sync
+var notifyRetryPolicy = retryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// chatMessageLimit largo máximo del mensaje para Discord (2000) con margen; también se usa en Slack
+const chatMessageLimit = 1900
+
+// Tipos de evento que se notifican
+const (
+	eventNewEpisodes = "new_episodes"
+	eventSyncFailed  = "sync_failed"
+	eventRunFailed   = "run_failed"
+	eventTest        = "test"
+)
+
+// notifyEvent evento enviado a los notificadores. El webhook genérico lo recibe tal cual en JSON.
+type notifyEvent struct {
+	Event    string          `json:"event"`
+	Time     string          `json:"time"`
+	Anime    string          `json:"anime,omitempty"`
+	Slug     string          `json:"slug,omitempty"`
+	URL      string          `json:"url,omitempty"`
+	File     string          `json:"file,omitempty"`
+	Episodes []ExportEpisode `json:"episodes,omitempty"`
+	Warning  string          `json:"warning,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Notifier envía un evento a un destino (webhook, chat, comando)
+type Notifier interface {
+	Name() string
+	Notify(event notifyEvent) error
+}
+
+// notifyConfig destinos indicados por flags; cada flag se puede repetir
+type notifyConfig struct {
+	Webhooks []string
+	Discord  []string
+	Slack    []string
+	Commands []string
+}
+
+// notification configuración de notificaciones de la ejecución actual
+var notification notifyConfig
+
+// notifiers destinos activos, creados por applyNotifyFlags
+var notifiers []Notifier
+
+// repeatFlag flag que acumula un valor por cada vez que se indica (sin separar por comas)
+type repeatFlag struct {
+	values *[]string
+}
+
+func (f repeatFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	return strings.Join(*f.values, " ")
+}
+
+func (f repeatFlag) Set(value string) error {
+	*f.values = append(*f.values, value)
+	return nil
+}
+
+// registerNotifyFlags agrega los flags de notificaciones a un FlagSet
+func registerNotifyFlags(fs *flag.FlagSet) {
+	fs.Var(repeatFlag{&notification.Webhooks}, "webhook", "URL que recibe cada evento en JSON (repetible)")
+	fs.Var(repeatFlag{&notification.Discord}, "discord-webhook", "Webhook de Discord (repetible)")
+	fs.Var(repeatFlag{&notification.Slack}, "slack-webhook", "Webhook entrante de Slack (repetible)")
+	fs.Var(repeatFlag{&notification.Commands}, "notify-command", "Comando de shell a ejecutar con cada evento; recibe el JSON por stdin (repetible)")
+}
+
+// applyNotifyFlags crea los notificadores una vez parseados los flags
+func applyNotifyFlags() error {
+	notifiers = nil
+
+	hooks := []struct {
+		urls   []string
+		format string
+	}{
+		{notification.Webhooks, "json"},
+		{notification.Discord, "discord"},
+		{notification.Slack, "slack"},
+	}
+	for _, hook := range hooks {
+		for _, link := range hook.urls {
+			u, err := url.Parse(link)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("URL de webhook inválida: %s", link)
+			}
+			notifiers = append(notifiers, &webhookNotifier{url: link, format: hook.format})
+		}
+	}
+
+	for _, command := range notification.Commands {
+		notifiers = append(notifiers, &commandNotifier{command: command})
+	}
+
+	return nil
+}
+
+// newNotifyEvent crea un evento con la hora actual
+func newNotifyEvent(eventType string) notifyEvent {
+	return notifyEvent{Event: eventType, Time: time.Now().Format(time.RFC3339)}
+}
+
+// message texto del evento para chats y para el comando
+func (e notifyEvent) message() string {
+	switch e.Event {
+	case eventNewEpisodes:
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "🆕 %s: %d episodios nuevos\n", e.Anime, len(e.Episodes))
+		for _, episode := range e.Episodes {
+			fmt.Fprintf(&builder, "• %s (%d enlaces)\n", episode.Name, len(episode.Downloads))
+		}
+		if e.Warning != "" {
+			fmt.Fprintf(&builder, "⚠️ %s\n", e.Warning)
+		}
+		if e.URL != "" {
+			builder.WriteString(e.URL)
+		}
+		return strings.TrimSpace(builder.String())
+	case eventSyncFailed:
+		return fmt.Sprintf("❌ Error sincronizando %s: %s", e.Anime, e.Error)
+	case eventRunFailed:
+		return fmt.Sprintf("❌ Sync fallido: %s", e.Error)
+	default:
+		return "🔔 Notificación de prueba de animeflv-downloader"
+	}
+}
+
+// truncateMessage recorta el mensaje al límite de los chats sin cortar caracteres
+func truncateMessage(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	return string(runes[:limit-1]) + "…"
+}
+
+// webhookNotifier envía el evento por POST: JSON genérico o mensaje de Discord/Slack
+type webhookNotifier struct {
+	url    string
+	format string // json, discord, slack
+}
+
+// Name describe el notificador sin mostrar la URL completa (los webhooks de chat incluyen el token)
+func (n *webhookNotifier) Name() string {
+	if u, err := url.Parse(n.url); err == nil {
+		return fmt.Sprintf("%s (%s)", n.format, u.Host)
+	}
+	return n.format
+}
+
+// payload arma el cuerpo según el destino
+func (n *webhookNotifier) payload(event notifyEvent) any {
+	switch n.format {
+	case "discord":
+		return map[string]string{
+			"username": "animeflv-downloader",
+			"content":  truncateMessage(event.message(), chatMessageLimit),
+		}
+	case "slack":
+		return map[string]string{"text": truncateMessage(event.message(), chatMessageLimit)}
+	default:
+		return event
+	}
+}
+
+// Notify envía el evento, reintentando los errores transitorios (5xx, 429 con Retry-After)
+func (n *webhookNotifier) Notify(event notifyEvent) error {
+	body, err := json.Marshal(n.payload(event))
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+
+	// Cliente propio: el webhook no es AnimeFLV (sin proxy, cookies, grabación ni límite por host)
+	client := &http.Client{Timeout: notifyTimeout}
+
+	return withRetry(notifyRetryPolicy, func() error {
+		req, err := http.NewRequest("POST", n.url, bytes.NewReader(body))
+		if err != nil {
+			return permanent(fmt.Errorf("error creando request: %v", err))
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "animeflv-downloader")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode >= 400 {
+			return &httpStatusError{
+				StatusCode: resp.StatusCode,
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
+
+		return nil
+	})
+}
+
+// commandNotifier ejecuta un comando de shell con el evento en JSON por stdin y en variables de entorno
+type commandNotifier struct {
+	command string
+}
+
+// Name describe el notificador
+func (n *commandNotifier) Name() string {
+	return "comando"
+}
+
+// Notify ejecuta el comando; falla si sale con error o supera notifyTimeout
+func (n *commandNotifier) Notify(event notifyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", n.command)
+	}
+
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"ANIMEFLV_EVENT="+event.Event,
+		"ANIMEFLV_ANIME="+event.Anime,
+		"ANIMEFLV_SLUG="+event.Slug,
+		"ANIMEFLV_URL="+event.URL,
+		"ANIMEFLV_FILE="+event.File,
+		"ANIMEFLV_EPISODES="+strconv.Itoa(len(event.Episodes)),
+		"ANIMEFLV_WARNING="+event.Warning,
+		"ANIMEFLV_ERROR="+event.Error,
+		"ANIMEFLV_MESSAGE="+event.message(),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("el comando superó %s", notifyTimeout)
+	}
+	if err != nil {
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return fmt.Errorf("%v: %s", err, truncateMessage(text, 200))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// notifyAll envía el evento a todos los notificadores; los fallos se informan pero no cortan el sync
+func notifyAll(event notifyEvent) {
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(event); err != nil {
+			log.Printf("⚠️  Notificación %s fallida: %v", notifier.Name(), err)
+		}
+	}
+}
+
+// notifySyncResult notifica los episodios nuevos o el error de un anime sincronizado
+func notifySyncResult(result syncResult) {
+	if len(notifiers) == 0 {
+		return
+	}
+
+	if len(result.NewEpisodes) > 0 {
+		event := newNotifyEvent(eventNewEpisodes)
+		event.Anime = result.Title
+		event.Slug = result.Slug
+		event.URL = urlBase + result.Link
+		event.File = result.File
+		event.Episodes = result.NewEpisodes
+		event.Warning = result.Warning
+		notifyAll(event)
+	}
+
+	if result.Err != nil {
+		event := newNotifyEvent(eventSyncFailed)
+		event.Anime = result.Title
+		event.Slug = result.Slug
+		event.URL = urlBase + result.Link
+		event.Error = result.Err.Error()
+		notifyAll(event)
+	}
+}
+
+// notifyRunFailure notifica un sync que no pudo ejecutarse (lista ilegible, error guardando, etc.)
+func notifyRunFailure(err error) {
+	if len(notifiers) == 0 {
+		return
+	}
+
+	event := newNotifyEvent(eventRunFailed)
+	event.Error = err.Error()
+	notifyAll(event)
+}
+
+// runNotify implementa el comando `notify`: envía un evento de prueba a los destinos indicados
+func runNotify(args []string) error {
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	registerNotifyFlags(fs)
+	fs.Parse(args)
+
+	if err := applyNotifyFlags(); err != nil {
+		return err
+	}
+
+	if len(notifiers) == 0 {
+		return fmt.Errorf("indica al menos un destino: --webhook, --discord-webhook, --slack-webhook o --notify-command")
+	}
+
+	failed := 0
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(newNotifyEvent(eventTest)); err != nil {
+			fmt.Printf("❌ %s: %v\n", notifier.Name(), err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %s\n", notifier.Name())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d de %d notificaciones fallaron", failed, len(notifiers))
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testNotifyEvent evento con dos episodios nuevos
+func testNotifyEvent() notifyEvent {
+	event := newNotifyEvent(eventNewEpisodes)
+	event.Anime = "Dr. Stone"
+	event.Slug = "dr-stone"
+	event.URL = urlBase + "/anime/dr-stone"
+	event.Episodes = []ExportEpisode{
+		{Episode: Episode{Name: "Episodio 24"}, Downloads: []Download{{ProviderName: "MEGA"}, {ProviderName: "Stape"}}},
+		{Episode: Episode{Name: "Episodio 25"}, Downloads: []Download{{ProviderName: "MEGA"}}},
+	}
+	event.Warning = "1 episodios sin enlaces, se reintentan en el próximo sync: Episodio 26"
+
+	return event
+}
+
+// fastNotifyRetries acorta las esperas entre reintentos durante el test
+func fastNotifyRetries(t *testing.T) {
+	previous := notifyRetryPolicy
+	notifyRetryPolicy = retryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	t.Cleanup(func() { notifyRetryPolicy = previous })
+}
+
+// captureServer responde con los códigos indicados (el último se repite) y guarda el último cuerpo recibido
+func captureServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32, *[]byte) {
+	t.Helper()
+
+	var requests atomic.Int32
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("petición inesperada: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests, &body
+}
+
+func TestWebhookNotifierJSON(t *testing.T) {
+	server, requests, body := captureServer(t, http.StatusNoContent)
+
+	notifier := &webhookNotifier{url: server.URL, format: "json"}
+	if err := notifier.Notify(testNotifyEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("%d peticiones; se esperaba 1", requests.Load())
+	}
+
+	var event notifyEvent
+	if err := json.Unmarshal(*body, &event); err != nil {
+		t.Fatalf("cuerpo no es JSON: %v", err)
+	}
+	if event.Event != eventNewEpisodes || event.Slug != "dr-stone" || len(event.Episodes) != 2 {
+		t.Errorf("evento = %+v", event)
+	}
+}
+
+func TestWebhookNotifierChatPayloads(t *testing.T) {
+	tests := []struct {
+		format string
+		field  string
+	}{
+		{"discord", "content"},
+		{"slack", "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			server, _, body := captureServer(t, http.StatusOK)
+
+			notifier := &webhookNotifier{url: server.URL, format: tt.format}
+			if err := notifier.Notify(testNotifyEvent()); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			var payload map[string]string
+			if err := json.Unmarshal(*body, &payload); err != nil {
+				t.Fatalf("cuerpo no es JSON: %v", err)
+			}
+
+			message := payload[tt.field]
+			if !strings.Contains(message, "Dr. Stone: 2 episodios nuevos") || !strings.Contains(message, "Episodio 25") ||
+				!strings.Contains(message, "Episodio 26") {
+				t.Errorf("%s = %q", tt.field, message)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierTruncatesLongMessages(t *testing.T) {
+	server, _, body := captureServer(t, http.StatusOK)
+
+	event := testNotifyEvent()
+	for range 200 {
+		event.Episodes = append(event.Episodes, ExportEpisode{Episode: Episode{Name: "Episodio especial sin número"}})
+	}
+
+	notifier := &webhookNotifier{url: server.URL, format: "discord"}
+	if err := notifier.Notify(event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(*body, &payload); err != nil {
+		t.Fatalf("cuerpo no es JSON: %v", err)
+	}
+	if length := len([]rune(payload["content"])); length > chatMessageLimit {
+		t.Errorf("mensaje de %d caracteres; el límite es %d", length, chatMessageLimit)
+	}
+}
+
+func TestWebhookNotifierRetries(t *testing.T) {
+	fastNotifyRetries(t)
+
+	t.Run("5xx transitorio", func(t *testing.T) {
+		server, requests, _ := captureServer(t, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+
+		notifier := &webhookNotifier{url: server.URL, format: "json"}
+		if err := notifier.Notify(testNotifyEvent()); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("%d peticiones; se esperaban 3", requests.Load())
+		}
+	})
+
+	t.Run("5xx persistente", func(t *testing.T) {
+		server, requests, _ := captureServer(t, http.StatusInternalServerError)
+
+		notifier := &webhookNotifier{url: server.URL, format: "json"}
+		if err := notifier.Notify(testNotifyEvent()); err == nil {
+			t.Fatal("se esperaba error")
+		}
+		if int(requests.Load()) != notifyRetryPolicy.MaxAttempts {
+			t.Errorf("%d peticiones; se esperaban %d", requests.Load(), notifyRetryPolicy.MaxAttempts)
+		}
+	})
+
+	t.Run("4xx permanente", func(t *testing.T) {
+		server, requests, _ := captureServer(t, http.StatusNotFound)
+
+		notifier := &webhookNotifier{url: server.URL, format: "json"}
+		if err := notifier.Notify(testNotifyEvent()); err == nil {
+			t.Fatal("se esperaba error")
+		}
+		if requests.Load() != 1 {
+			t.Errorf("%d peticiones; se esperaba 1", requests.Load())
+		}
+	})
+}
+
+func TestCommandNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("el comando de prueba usa sh")
+	}
+
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "stdin.json")
+	envFile := filepath.Join(dir, "env.txt")
+
+	notifier := &commandNotifier{
+		command: `cat > "` + stdinFile + `" && printf '%s|%s|%s' "$ANIMEFLV_EVENT" "$ANIMEFLV_SLUG" "$ANIMEFLV_EPISODES" > "` + envFile + `"`,
+	}
+	if err := notifier.Notify(testNotifyEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	content, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event notifyEvent
+	if err := json.Unmarshal(content, &event); err != nil {
+		t.Fatalf("stdin no es JSON: %v", err)
+	}
+	if event.Anime != "Dr. Stone" || len(event.Episodes) != 2 {
+		t.Errorf("evento = %+v", event)
+	}
+
+	env, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(env), "new_episodes|dr-stone|2"; got != want {
+		t.Errorf("variables = %q; se esperaba %q", got, want)
+	}
+
+	failing := &commandNotifier{command: "echo sin permiso >&2; exit 3"}
+	err = failing.Notify(testNotifyEvent())
+	if err == nil || !strings.Contains(err.Error(), "sin permiso") {
+		t.Errorf("error = %v; se esperaba la salida del comando", err)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRateHosts hosts limitados por defecto: solo AnimeFLV, los proveedores y CDNs tienen sus propios límites
+const defaultRateHosts = "animeflv"
+
+// tokenBucket estado del limitador para un host
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// hostRateLimiter limita las peticiones por segundo a cada host (token bucket)
+type hostRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	hosts   []string // hosts limitados: "animeflv" (AnimeFLV), "all" (todos) o dominios
+	buckets map[string]*tokenBucket
+}
+
+// newHostRateLimiter crea un limitador para los hosts indicados; rate <= 0 desactiva la limitación
+func newHostRateLimiter(rate float64, burst int, hosts []string) *hostRateLimiter {
+	return &hostRateLimiter{
+		rate:    rate,
+		burst:   max(burst, 1),
+		hosts:   hosts,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// parseRateHosts interpreta la lista de hosts de --rate-hosts (separados por comas)
+func parseRateHosts(text string) []string {
+	var hosts []string
+	for _, host := range strings.Split(text, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// limits indica si el host está sujeto al límite (un dominio incluye sus subdominios)
+func (l *hostRateLimiter) limits(host string) bool {
+	for _, limited := range l.hosts {
+		switch {
+		case limited == "all":
+			return true
+		case limited == "animeflv":
+			if isAnimeFLVHost(host) {
+				return true
+			}
+		case host == limited || strings.HasSuffix(host, "."+limited):
+			return true
+		}
+	}
+
+	return false
+}
+
+// Wait bloquea hasta que haya un turno disponible para el host
+func (l *hostRateLimiter) Wait(host string) {
+	if l == nil || l.rate <= 0 {
+		return
+	}
+
+	host = strings.ToLower(host)
+	if !l.limits(host) {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	bucket, exists := l.buckets[host]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(l.burst), last: now}
+		l.buckets[host] = bucket
+	}
+
+	// Recargar tokens según el tiempo transcurrido
+	bucket.tokens = min(bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate, float64(l.burst))
+	bucket.last = now
+
+	// Reservar el turno; si quedan tokens negativos hay que esperar
+	bucket.tokens--
+	wait := time.Duration(0)
+	if bucket.tokens < 0 {
+		wait = time.Duration(-bucket.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// WaitURL bloquea hasta que haya un turno disponible para el host de la URL
+func (l *hostRateLimiter) WaitURL(link string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return
+	}
+
+	l.Wait(u.Hostname())
+}
+
+// rateLimitExemptKey marca en el contexto las peticiones que no pasan por el limitador
+type rateLimitExemptKey struct{}
+
+// withoutRateLimit marca la petición como exenta del límite (descargas de archivos y segmentos HLS):
+// son transferencias largas o numerosas a un CDN y limitarlas solo haría más lenta la descarga
+func withoutRateLimit(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), rateLimitExemptKey{}, true))
+}
+
+// rateLimitExempt indica si la petición fue marcada con withoutRateLimit
+func rateLimitExempt(req *http.Request) bool {
+	exempt, _ := req.Context().Value(rateLimitExemptKey{}).(bool)
+	return exempt
+}
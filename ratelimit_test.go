@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHostRateLimiterLimits(t *testing.T) {
+	tests := []struct {
+		hosts string
+		host  string
+		want  bool
+	}{
+		{"animeflv", "www3.animeflv.net", true},
+		{"animeflv", "animeflv.net", true},
+		{"animeflv", "streamtape.com", false},
+		{"animeflv", "cdn.example.com", false},
+		{"all", "cdn.example.com", true},
+		{"animeflv, streamtape.com", "streamtape.com", true},
+		{"streamtape.com", "tapecontent.streamtape.com", true},
+		{"streamtape.com", "notstreamtape.com", false},
+		{"", "www3.animeflv.net", false},
+	}
+
+	for _, tt := range tests {
+		limiter := newHostRateLimiter(2, 1, parseRateHosts(tt.hosts))
+		if got := limiter.limits(tt.host); got != tt.want {
+			t.Errorf("limits(%q) con %q = %v; se esperaba %v", tt.host, tt.hosts, got, tt.want)
+		}
+	}
+}
+
+func TestWithoutRateLimit(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://cdn.example.com/video.mp4", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rateLimitExempt(req) {
+		t.Error("una petición sin marcar no debe estar exenta")
+	}
+	if !rateLimitExempt(withoutRateLimit(req)) {
+		t.Error("withoutRateLimit debe marcar la petición como exenta")
+	}
+	// doWithRetryOnce clona la petición en cada intento: la marca debe sobrevivir al clon
+	exempt := withoutRateLimit(req)
+	if !rateLimitExempt(exempt.Clone(exempt.Context())) {
+		t.Error("la marca debe conservarse al clonar la petición")
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxRecordSize tamaño máximo de una respuesta grabada (los videos y archivos grandes no se graban)
+const maxRecordSize = 10 << 20
+
+// recorder grabador activo (--record) o fuente de respuestas (--replay); nil si no se usa
+var recorder *pageRecorder
+
+// recordedResponse respuesta grabada en disco
+type recordedResponse struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Status   int         `json:"status"`
+	Headers  http.Header `json:"headers,omitempty"`
+	Body     string      `json:"body"`
+	Body64   string      `json:"body_base64,omitempty"` // cuerpo que no es UTF-8 válido (JSON lo alteraría)
+	Source   string      `json:"source"`                // http o chrome
+	Recorded time.Time   `json:"recorded"`
+}
+
+// pageRecorder guarda y lee respuestas grabadas, un archivo JSON por método y URL
+type pageRecorder struct {
+	dir    string
+	replay bool
+}
+
+// newPageRecorder crea el grabador; en modo replay el directorio debe existir
+func newPageRecorder(dir string, replay bool) (*pageRecorder, error) {
+	if replay {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("directorio de grabaciones no encontrado: %s", dir)
+		}
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de grabaciones: %v", err)
+	}
+
+	return &pageRecorder{dir: dir, replay: replay}, nil
+}
+
+// replaying indica si las páginas se sirven desde las grabaciones
+func (r *pageRecorder) replaying() bool {
+	return r != nil && r.replay
+}
+
+// recording indica si se están grabando las respuestas
+func (r *pageRecorder) recording() bool {
+	return r != nil && !r.replay
+}
+
+// path devuelve el archivo de la grabación; el host al inicio facilita encontrarla a mano
+func (r *pageRecorder) path(method, link string) string {
+	host := "sin-host"
+	if u, err := url.Parse(link); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	sum := sha256.Sum256([]byte(method + " " + link))
+	return filepath.Join(r.dir, host+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// Save guarda una respuesta grabada
+func (r *pageRecorder) Save(recorded recordedResponse) error {
+	if recorded.Recorded.IsZero() {
+		recorded.Recorded = time.Now()
+	}
+
+	if !utf8.ValidString(recorded.Body) {
+		recorded.Body64 = base64.StdEncoding.EncodeToString([]byte(recorded.Body))
+		recorded.Body = ""
+	}
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando grabación: %v", err)
+	}
+
+	if err := os.WriteFile(r.path(recorded.Method, recorded.URL), data, 0644); err != nil {
+		return fmt.Errorf("error escribiendo grabación: %v", err)
+	}
+
+	return nil
+}
+
+// Load lee la respuesta grabada para el método y la URL
+func (r *pageRecorder) Load(method, link string) (*recordedResponse, error) {
+	data, err := os.ReadFile(r.path(method, link))
+	if os.IsNotExist(err) {
+		return nil, permanent(fmt.Errorf("sin grabación para %s %s", method, link))
+	}
+	if err != nil {
+		return nil, permanent(fmt.Errorf("error leyendo grabación: %v", err))
+	}
+
+	var recorded recordedResponse
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, permanent(fmt.Errorf("grabación inválida para %s: %v", link, err))
+	}
+
+	if recorded.Body64 != "" {
+		body, err := base64.StdEncoding.DecodeString(recorded.Body64)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("grabación inválida para %s: %v", link, err))
+		}
+		recorded.Body, recorded.Body64 = string(body), ""
+	}
+
+	return &recorded, nil
+}
+
+// isRecordableType indica si el tipo de contenido es texto (HTML, JSON, JS, playlists...)
+func isRecordableType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.Contains(mediaType, "json") ||
+		strings.Contains(mediaType, "xml") ||
+		strings.Contains(mediaType, "javascript") ||
+		strings.Contains(mediaType, "mpegurl")
+}
+
+// recordingTransport graba las respuestas de texto que pasan por el transporte
+type recordingTransport struct {
+	base     http.RoundTripper
+	recorder *pageRecorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isRecordableType(resp.Header.Get("Content-Type")) || resp.ContentLength > maxRecordSize {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRecordSize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Respuesta más grande de lo esperado: se entrega completa sin grabarla
+	if len(body) > maxRecordSize {
+		resp.Body = &decompressedBody{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), raw: resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = t.recorder.Save(recordedResponse{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Status:  resp.StatusCode,
+		Headers: resp.Header,
+		Body:    string(body),
+		Source:  "http",
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+
+	return resp, nil
+}
+
+// replayTransport responde desde las grabaciones sin acceder a la red
+type replayTransport struct {
+	recorder *pageRecorder
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	recorded, err := t.recorder.Load(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	header := recorded.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	pages := map[string]string{
+		"/anime/naruto": "<html>\r\n<body>Episodio 1 — ñandú ✓</body>\r\n</html>\n\x00",
+		// Una página en Latin-1 no es UTF-8 válido y debe volver igual
+		"/latin1": "<p>Edici\xf3n espa\xf1ola</p>",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Test", "grabado")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, pages[r.URL.Path])
+	}))
+
+	dir := t.TempDir()
+	record, err := newPageRecorder(dir, false)
+	if err != nil {
+		t.Fatalf("newPageRecorder: %v", err)
+	}
+	recordClient := &http.Client{Transport: &recordingTransport{base: http.DefaultTransport, recorder: record}}
+
+	for path, want := range pages {
+		resp, err := recordClient.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		// Grabar no altera la respuesta que recibe el llamador
+		if string(body) != want {
+			t.Errorf("%s grabando: cuerpo %q, se esperaba %q", path, body, want)
+		}
+	}
+
+	// Sin red: todo debe salir de las grabaciones
+	server.Close()
+
+	replay, err := newPageRecorder(dir, true)
+	if err != nil {
+		t.Fatalf("newPageRecorder (replay): %v", err)
+	}
+	replayClient := &http.Client{Transport: &replayTransport{recorder: replay}}
+
+	for path, want := range pages {
+		resp, err := replayClient.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("replay %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if string(body) != want {
+			t.Errorf("%s: cuerpo %q, se esperaba %q", path, body, want)
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Test") != "grabado" {
+			t.Errorf("%s: código %d, headers %v", path, resp.StatusCode, resp.Header)
+		}
+	}
+
+	// Una URL no grabada falla sin reintentos
+	_, err = replayClient.Get(server.URL + "/sin-grabar")
+	if err == nil || !strings.Contains(err.Error(), "sin grabación") {
+		t.Fatalf("error = %v; se esperaba uno de grabación inexistente", err)
+	}
+	if isRetryable(err) {
+		t.Errorf("una grabación inexistente no debería reintentarse: %v", err)
+	}
+
+	if _, err := newPageRecorder(dir+"/no-existe", true); err == nil {
+		t.Error("se esperaba error al reproducir desde un directorio inexistente")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resolved representa el enlace directo obtenido desde la página de un proveedor
+type Resolved struct {
+	URL      string            `json:"url"`
+	FileName string            `json:"file_name,omitempty"`
+	Size     int64             `json:"size,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// resolverFunc obtiene el enlace directo a partir de la página del proveedor
+type resolverFunc func(pageURL string) (*Resolved, error)
+
+// errNoResolver indica que el proveedor no tiene resolvedor registrado
+var errNoResolver = errors.New("proveedor sin resolvedor")
+
+// resolvers asocia el dominio del proveedor con su resolvedor
+var resolvers = map[string]resolverFunc{
+	"mediafire.com":  resolveMediafire,
+	"streamtape.com": resolveStreamtape,
+	"streamtape.net": resolveStreamtape,
+	"streamtape.to":  resolveStreamtape,
+	"strtape.cloud":  resolveStreamtape,
+	"streamta.pe":    resolveStreamtape,
+	"yourupload.com": resolveYourUpload,
+	"streamwish.to":  resolveStreamWish,
+	"streamwish.com": resolveStreamWish,
+	"sfastwish.com":  resolveStreamWish,
+	"wishembed.pro":  resolveStreamWish,
+}
+
+// findResolver busca el resolvedor correspondiente al dominio del enlace
+func findResolver(link string) resolverFunc {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for domain, resolve := range resolvers {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return resolve
+		}
+	}
+
+	return nil
+}
+
+// fetchProviderPage descarga la página de un proveedor y devuelve su HTML y la URL final
+func fetchProviderPage(pageURL, referer string) (string, *url.URL, error) {
+	req, err := newBrowserRequest("GET", pageURL, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+
+	resp, err := doWithRetry(newHTTPClient(15*time.Second), req)
+	if err != nil {
+		return "", nil, fmt.Errorf("error haciendo request HTTP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", nil, fmt.Errorf("error HTTP: código de estado %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("error leyendo respuesta: %v", err)
+	}
+
+	return string(body), resp.Request.URL, nil
+}
+
+// resolveDownload obtiene el enlace directo de un enlace de descarga
+func resolveDownload(link string) (*Resolved, error) {
+	resolve := findResolver(link)
+	if resolve == nil {
+		return nil, errNoResolver
+	}
+
+	return resolve(link)
+}
+
+// resolveDownloads completa los enlaces directos de una lista de descargas
+func resolveDownloads(downloads []Download) {
+	for i := range downloads {
+		resolved, err := resolveDownload(downloads[i].DownloadURL)
+		if err != nil {
+			if !errors.Is(err, errNoResolver) {
+				fmt.Fprintf(os.Stderr, "\n   ⚠️  No se pudo resolver %s: %v", downloads[i].ProviderName, err)
+			}
+			continue
+		}
+
+		downloads[i].Direct = resolved
+	}
+}
+
+// parseSize convierte un tamaño legible (ej. "245.3MB") a bytes
+func parseSize(text string) int64 {
+	text = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(text), " ", ""))
+
+	// "1,234.5" usa la coma como separador de miles; sin punto la coma es decimal ("245,3")
+	if strings.Contains(text, ".") {
+		text = strings.ReplaceAll(text, ",", "")
+	} else {
+		text = strings.ReplaceAll(text, ",", ".")
+	}
+
+	units := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	for _, unit := range units {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0
+			}
+			return int64(value * unit.multiplier)
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// retryPolicy define cuántas veces y con qué espera se reintenta una operación
+type retryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// defaultRetryPolicy política usada por todas las peticiones HTTP y de Chrome
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   1 * time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// httpStatusError representa una respuesta HTTP con código de error
+type httpStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("error HTTP: código de estado %d", e.StatusCode)
+}
+
+// permanentError marca un error que no tiene sentido reintentar
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// permanent envuelve un error para que no se reintente (ej. error de parseo)
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isRetryable clasifica un error como transitorio (reintentable) o permanente
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var permanentErr *permanentError
+	if errors.As(err, &permanentErr) {
+		return false
+	}
+
+	// Los desafíos anti-bot se resuelven con Chrome, no reintentando
+	if errors.Is(err, errChallenge) {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests,
+			http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// Cancelado por el usuario (Ctrl+C) o Chrome no instalado: reintentar no cambia nada
+	if errors.Is(err, context.Canceled) || errors.Is(err, exec.ErrNotFound) {
+		return false
+	}
+
+	// Solo se reintentan fallos de red conocidos como transitorios; el resto (certificados
+	// inválidos, URLs o esquemas inválidos, errores de parseo) se devuelve de inmediato
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNABORTED) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsTimeout) {
+		return true
+	}
+
+	// Chrome informa los fallos de red como texto (ej. "page load error net::ERR_CONNECTION_RESET")
+	message := err.Error()
+	for _, chromeErr := range chromeTransientErrors {
+		if strings.Contains(message, chromeErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// chromeTransientErrors errores de red de Chrome que vale la pena reintentar
+var chromeTransientErrors = []string{
+	"net::ERR_CONNECTION_RESET",
+	"net::ERR_CONNECTION_REFUSED",
+	"net::ERR_CONNECTION_CLOSED",
+	"net::ERR_CONNECTION_TIMED_OUT",
+	"net::ERR_TIMED_OUT",
+	"net::ERR_EMPTY_RESPONSE",
+	"net::ERR_NETWORK_CHANGED",
+}
+
+// backoffDelay calcula la espera antes del siguiente intento (exponencial con jitter)
+func (p retryPolicy) backoffDelay(attempt int, err error) time.Duration {
+	// Respetar Retry-After si el servidor lo indicó
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, p.MaxDelay)
+	}
+
+	delay := min(p.BaseDelay<<attempt, p.MaxDelay)
+
+	// Mitad fija y mitad aleatoria para no sincronizar reintentos
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// withRetry ejecuta la operación reintentando los errores transitorios
+func withRetry(policy retryPolicy, operation func() error) error {
+	var err error
+
+	for attempt := range policy.MaxAttempts {
+		err = operation()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+
+		if attempt < policy.MaxAttempts-1 {
+			time.Sleep(policy.backoffDelay(attempt, err))
+		}
+	}
+
+	return err
+}
+
+// parseRetryAfter interpreta la cabecera Retry-After (segundos o fecha HTTP)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+// doWithRetry ejecuta una petición HTTP con la política de reintentos.
+// Las respuestas con código >= 400 se devuelven como *httpStatusError.
+// Si la respuesta es un desafío anti-bot se resuelve una vez con Chrome y se repite la petición.
+func doWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := doWithRetryOnce(client, req)
+	if !errors.Is(err, errChallenge) {
+		return resp, err
+	}
+
+	if err := solveChallenge(req.URL.String()); err != nil {
+		return nil, err
+	}
+
+	return doWithRetryOnce(client, req)
+}
+
+// doWithRetryOnce ejecuta la petición con reintentos sin intentar resolver desafíos
+func doWithRetryOnce(client *http.Client, req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+
+	err := withRetry(defaultRetryPolicy, func() error {
+		attemptReq := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return permanent(err)
+			}
+			attemptReq.Body = body
+		}
+
+		// Usar el user agent de Chrome si se resolvió un desafío (cf_clearance depende de él)
+		if userAgent := sessionUserAgent(); userAgent != "" {
+			attemptReq.Header.Set("User-Agent", userAgent)
+		}
+
+		// Cada intento respeta el límite de peticiones del host, salvo descargas y segmentos
+		if !rateLimitExempt(attemptReq) {
+			rateLimiter.Wait(attemptReq.URL.Hostname())
+		}
+
+		var err error
+		resp, err = client.Do(attemptReq)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode >= 400 {
+			defer resp.Body.Close()
+
+			if isChallengeResponse(resp) {
+				return errChallenge
+			}
+
+			return &httpStatusError{
+				StatusCode: resp.StatusCode,
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
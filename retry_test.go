@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+// timeoutError implementa net.Error como un timeout de red
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"503", &httpStatusError{StatusCode: 503}, true},
+		{"429", &httpStatusError{StatusCode: 429}, true},
+		{"408", &httpStatusError{StatusCode: 408}, true},
+		{"404", &httpStatusError{StatusCode: 404}, false},
+		{"403", &httpStatusError{StatusCode: 403}, false},
+		{"timeout de red", &url.Error{Op: "Get", URL: "https://example.com", Err: timeoutError{}}, true},
+		{"deadline", fmt.Errorf("chrome: %w", context.DeadlineExceeded), true},
+		{"conexión reiniciada", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"conexión rechazada", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"EOF", &url.Error{Op: "Get", URL: "https://example.com", Err: io.EOF}, true},
+		{"EOF inesperado", fmt.Errorf("error leyendo respuesta: %w", io.ErrUnexpectedEOF), true},
+		{"DNS temporal", &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, true},
+		{"DNS inexistente", &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}, false},
+		{"chrome sin conexión", errors.New("page load error net::ERR_CONNECTION_RESET"), true},
+		{"chrome dominio inexistente", errors.New("page load error net::ERR_NAME_NOT_RESOLVED"), false},
+		{"cancelado", &url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled}, false},
+		{"certificado", &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}, false},
+		{"esquema inválido", &url.Error{Op: "Get", URL: "ftp://example.com", Err: errors.New(`unsupported protocol scheme "ftp"`)}, false},
+		{"URL inválida", &url.Error{Op: "parse", URL: "http://[::1", Err: errors.New("missing ']' in host")}, false},
+		{"permanente", permanent(&httpStatusError{StatusCode: 503}), false},
+		{"desafío", fmt.Errorf("http: %w", errChallenge), false},
+		{"chrome no instalado", exec.ErrNotFound, false},
+		{"error desconocido", errors.New("no se encontraron enlaces"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: isRetryable(%v) = %v; se esperaba %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
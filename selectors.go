@@ -0,0 +1,497 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v3"
+)
+
+// selectorProfileVersion versión más reciente del formato de perfil de selectores
+const selectorProfileVersion = 5
+
+//go:embed selectors.json
+var defaultSelectorsJSON []byte
+
+// selectorProfile selectores CSS usados para leer las páginas de AnimeFLV
+type selectorProfile struct {
+	Version   int               `json:"version" yaml:"version"`
+	Search    searchSelectors   `json:"search" yaml:"search"`
+	Episodes  episodeSelectors  `json:"episodes" yaml:"episodes"`
+	Downloads downloadSelectors `json:"downloads" yaml:"downloads"`
+	Details   detailSelectors   `json:"details" yaml:"details"`
+	Latest    latestSelectors   `json:"latest" yaml:"latest"`
+	Airing    airingSelectors   `json:"airing" yaml:"airing"`
+}
+
+// searchSelectors resultados de búsqueda; link es relativo al item, title al link
+// y el resto de campos de la tarjeta al item. next_page es relativo a la página.
+type searchSelectors struct {
+	Item     string `json:"item" yaml:"item"`
+	Link     string `json:"link" yaml:"link"`
+	Title    string `json:"title" yaml:"title"`
+	Type     string `json:"type" yaml:"type"`
+	Cover    string `json:"cover" yaml:"cover"`
+	Rating   string `json:"rating" yaml:"rating"`
+	Synopsis string `json:"synopsis" yaml:"synopsis"`
+	NextPage string `json:"next_page" yaml:"next_page"`
+}
+
+// episodeSelectors lista de episodios; link es relativo al item y name al link
+type episodeSelectors struct {
+	Item string `json:"item" yaml:"item"`
+	Link string `json:"link" yaml:"link"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// downloadSelectors tabla de descargas; las columnas empiezan en 0
+type downloadSelectors struct {
+	Row            string `json:"row" yaml:"row"`
+	Cell           string `json:"cell" yaml:"cell"`
+	ProviderColumn int    `json:"provider_column" yaml:"provider_column"`
+	LinkColumn     int    `json:"link_column" yaml:"link_column"`
+	Link           string `json:"link" yaml:"link"`
+}
+
+// detailSelectors ficha del anime; related_link es relativo a related_item y el resto a la página
+type detailSelectors struct {
+	Title       string `json:"title" yaml:"title"`
+	AltTitles   string `json:"alt_titles" yaml:"alt_titles"`
+	Type        string `json:"type" yaml:"type"`
+	Cover       string `json:"cover" yaml:"cover"`
+	Synopsis    string `json:"synopsis" yaml:"synopsis"`
+	Genres      string `json:"genres" yaml:"genres"`
+	Status      string `json:"status" yaml:"status"`
+	Score       string `json:"score" yaml:"score"`
+	RelatedItem string `json:"related_item" yaml:"related_item"`
+	RelatedLink string `json:"related_link" yaml:"related_link"`
+}
+
+// latestSelectors últimos episodios de la portada; link es relativo al item y el resto al link
+type latestSelectors struct {
+	Item    string `json:"item" yaml:"item"`
+	Link    string `json:"link" yaml:"link"`
+	Anime   string `json:"anime" yaml:"anime"`
+	Episode string `json:"episode" yaml:"episode"`
+}
+
+// airingSelectors lista de series en emisión de la portada; link es relativo al item y type al link
+type airingSelectors struct {
+	Item string `json:"item" yaml:"item"`
+	Link string `json:"link" yaml:"link"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// selectors perfil de selectores activo
+var selectors = defaultSelectorProfile()
+
+// defaultSelectorProfile devuelve el perfil incluido en el binario
+func defaultSelectorProfile() *selectorProfile {
+	var profile selectorProfile
+	if err := json.Unmarshal(defaultSelectorsJSON, &profile); err != nil {
+		panic(fmt.Sprintf("perfil de selectores por defecto inválido: %v", err))
+	}
+
+	return &profile
+}
+
+// loadSelectorProfile carga un perfil JSON o YAML sobre el perfil por defecto.
+// Los campos que no aparecen en el archivo conservan su valor por defecto.
+func loadSelectorProfile(file string) (*selectorProfile, error) {
+	profile := defaultSelectorProfile()
+	if file == "" {
+		return profile, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo perfil de selectores: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, profile)
+	default:
+		err = json.Unmarshal(data, profile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parseando perfil de selectores %s: %v", file, err)
+	}
+
+	if err := profile.validate(); err != nil {
+		return nil, fmt.Errorf("perfil de selectores %s inválido: %v", file, err)
+	}
+
+	return profile, nil
+}
+
+// validate comprueba la versión y que todos los selectores sean CSS válido
+func (p *selectorProfile) validate() error {
+	if p.Version > selectorProfileVersion {
+		return fmt.Errorf("versión %d no soportada (máxima %d)", p.Version, selectorProfileVersion)
+	}
+
+	fields := map[string]string{
+		"search.item":    p.Search.Item,
+		"search.link":    p.Search.Link,
+		"search.title":   p.Search.Title,
+		"episodes.item":  p.Episodes.Item,
+		"episodes.link":  p.Episodes.Link,
+		"episodes.name":  p.Episodes.Name,
+		"downloads.row":  p.Downloads.Row,
+		"downloads.cell": p.Downloads.Cell,
+		"downloads.link": p.Downloads.Link,
+	}
+	for name, selector := range fields {
+		if strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("%s está vacío", name)
+		}
+		if _, err := cascadia.Compile(selector); err != nil {
+			return fmt.Errorf("%s: selector inválido %q: %v", name, selector, err)
+		}
+	}
+
+	// Selectores opcionales: vacíos desactivan el dato correspondiente
+	optional := map[string]string{
+		"search.type":          p.Search.Type,
+		"search.cover":         p.Search.Cover,
+		"search.rating":        p.Search.Rating,
+		"search.synopsis":      p.Search.Synopsis,
+		"search.next_page":     p.Search.NextPage,
+		"details.title":        p.Details.Title,
+		"details.alt_titles":   p.Details.AltTitles,
+		"details.type":         p.Details.Type,
+		"details.cover":        p.Details.Cover,
+		"details.synopsis":     p.Details.Synopsis,
+		"details.genres":       p.Details.Genres,
+		"details.status":       p.Details.Status,
+		"details.score":        p.Details.Score,
+		"details.related_item": p.Details.RelatedItem,
+		"details.related_link": p.Details.RelatedLink,
+		"latest.item":          p.Latest.Item,
+		"latest.link":          p.Latest.Link,
+		"latest.anime":         p.Latest.Anime,
+		"latest.episode":       p.Latest.Episode,
+		"airing.item":          p.Airing.Item,
+		"airing.link":          p.Airing.Link,
+		"airing.type":          p.Airing.Type,
+	}
+	for name, selector := range optional {
+		if selector == "" {
+			continue
+		}
+		if _, err := cascadia.Compile(selector); err != nil {
+			return fmt.Errorf("%s: selector inválido %q: %v", name, selector, err)
+		}
+	}
+
+	if p.Downloads.ProviderColumn < 0 || p.Downloads.LinkColumn < 0 {
+		return fmt.Errorf("las columnas de downloads no pueden ser negativas")
+	}
+
+	return nil
+}
+
+// searchResults extrae los animes de una página de búsqueda
+func (p *selectorProfile) searchResults(doc *goquery.Document) []Anime {
+	var animesList []Anime
+
+	doc.Find(p.Search.Item).Each(func(i int, s *goquery.Selection) {
+		animeLink := s.Find(p.Search.Link)
+		animeName := animeLink.Find(p.Search.Title).Text()
+		href, exists := animeLink.Attr("href")
+
+		if exists && animeName != "" {
+			animesList = append(animesList, Anime{
+				Name:     strings.TrimSpace(animeName),
+				Link:     href,
+				Type:     normalizeAnimeType(selectionText(s, p.Search.Type)),
+				Cover:    selectionImage(s, p.Search.Cover),
+				Rating:   parseRating(selectionText(s, p.Search.Rating)),
+				Synopsis: selectionText(s, p.Search.Synopsis),
+			})
+		}
+	})
+
+	return animesList
+}
+
+// nextSearchPage devuelve el enlace a la siguiente página de resultados ("" si es la última)
+func (p *selectorProfile) nextSearchPage(doc *goquery.Document) string {
+	if p.Search.NextPage == "" {
+		return ""
+	}
+
+	href, _ := doc.Find(p.Search.NextPage).First().Attr("href")
+	if href == "#" {
+		return ""
+	}
+
+	return strings.TrimSpace(href)
+}
+
+// selectionText devuelve el texto del primer elemento que coincide, con los espacios normalizados
+func selectionText(s *goquery.Selection, selector string) string {
+	if selector == "" {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(s.Find(selector).First().Text()), " ")
+}
+
+// selectionImage devuelve la URL de la imagen, contemplando la carga diferida (data-src)
+func selectionImage(s *goquery.Selection, selector string) string {
+	if selector == "" {
+		return ""
+	}
+
+	img := s.Find(selector).First()
+	for _, attr := range []string{"data-src", "src"} {
+		if src, exists := img.Attr(attr); exists && src != "" {
+			return absoluteURL(src)
+		}
+	}
+
+	return ""
+}
+
+// episodes extrae la lista de episodios de la página de un anime
+func (p *selectorProfile) episodes(doc *goquery.Document) []Episode {
+	var episodesList []Episode
+
+	doc.Find(p.Episodes.Item).Each(func(i int, s *goquery.Selection) {
+		episodeLink := s.Find(p.Episodes.Link)
+		episodeName := episodeLink.Find(p.Episodes.Name).Text()
+		href, exists := episodeLink.Attr("href")
+
+		if exists && episodeName != "" {
+			episodesList = append(episodesList, Episode{
+				Name: strings.TrimSpace(episodeName),
+				Link: href,
+			})
+		}
+	})
+
+	return episodesList
+}
+
+// downloadTable extrae los enlaces de la tabla de descargas de un episodio
+func (p *selectorProfile) downloadTable(doc *goquery.Document) []Download {
+	var downloadList []Download
+
+	columns := max(p.Downloads.ProviderColumn, p.Downloads.LinkColumn) + 1
+
+	doc.Find(p.Downloads.Row).Each(func(i int, s *goquery.Selection) {
+		tds := s.Find(p.Downloads.Cell)
+		if tds.Length() >= columns {
+			providerName := strings.TrimSpace(tds.Eq(p.Downloads.ProviderColumn).Text())
+			downloadLink := tds.Eq(p.Downloads.LinkColumn).Find(p.Downloads.Link)
+			href, exists := downloadLink.Attr("href")
+
+			if exists && providerName != "" {
+				downloadList = append(downloadList, Download{
+					ProviderName: providerName,
+					DownloadURL:  href,
+				})
+			}
+		}
+	})
+
+	return downloadList
+}
+
+// selectorPageKind tipo de página de AnimeFLV según su URL (search, episodes, downloads, latest)
+func selectorPageKind(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil || !strings.HasSuffix(u.Hostname(), "animeflv.net") {
+		return ""
+	}
+
+	switch {
+	case u.Path == "" || u.Path == "/":
+		return "latest"
+	case strings.HasPrefix(u.Path, "/browse"):
+		return "search"
+	case strings.HasPrefix(u.Path, "/anime/"):
+		return "episodes"
+	case strings.HasPrefix(u.Path, "/ver/"):
+		return "downloads"
+	}
+
+	return ""
+}
+
+// selectorTestPage página guardada sobre la que se prueba un perfil
+type selectorTestPage struct {
+	Name string
+	Kind string // vacío si no se conoce (HTML suelto)
+	HTML string
+}
+
+// loadSelectorTestPages lee archivos .html y grabaciones .json (--record) de archivos o directorios
+func loadSelectorTestPages(paths []string) ([]selectorTestPage, error) {
+	var pages []selectorTestPage
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error accediendo a %s: %v", path, err)
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			files, err = filepath.Glob(filepath.Join(path, "*"))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".html", ".htm":
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return nil, fmt.Errorf("error leyendo %s: %v", file, err)
+				}
+				pages = append(pages, selectorTestPage{Name: file, HTML: string(data)})
+
+			case ".json":
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return nil, fmt.Errorf("error leyendo %s: %v", file, err)
+				}
+
+				var recorded recordedResponse
+				if err := json.Unmarshal(data, &recorded); err != nil || recorded.URL == "" {
+					continue
+				}
+
+				// Solo interesan las páginas de AnimeFLV, no las de proveedores
+				kind := selectorPageKind(recorded.URL)
+				if kind == "" || recorded.Status != 200 {
+					continue
+				}
+				pages = append(pages, selectorTestPage{Name: recorded.URL, Kind: kind, HTML: recorded.Body})
+			}
+		}
+	}
+
+	return pages, nil
+}
+
+// testSelectorPage cuenta lo que encuentra el perfil en la página.
+// Pasa si encuentra algo del tipo esperado (o de cualquier tipo si no se conoce).
+func testSelectorPage(profile *selectorProfile, page selectorTestPage) (map[string]int, bool, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.HTML))
+	if err != nil {
+		return nil, false, fmt.Errorf("error parseando HTML: %v", err)
+	}
+
+	counts := map[string]int{
+		"search":    len(profile.searchResults(doc)),
+		"episodes":  len(profile.episodes(doc)),
+		"downloads": len(profile.downloadTable(doc)),
+		"latest":    len(profile.latestEpisodes(doc)),
+	}
+
+	if page.Kind != "" {
+		return counts, counts[page.Kind] > 0, nil
+	}
+
+	return counts, counts["search"]+counts["episodes"]+counts["downloads"]+counts["latest"] > 0, nil
+}
+
+// runSelectors implementa el comando `selectors test|show`
+func runSelectors(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: selectors test [--selectors archivo] páginas.html|directorio... | selectors show [--selectors archivo]")
+	}
+
+	fs := flag.NewFlagSet("selectors "+args[0], flag.ExitOnError)
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	fs.Parse(args[1:])
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show":
+		data, err := json.MarshalIndent(profile, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+
+	case "test":
+		return testSelectorProfile(profile, fs.Args())
+
+	default:
+		return fmt.Errorf("subcomando desconocido: selectors %s", args[0])
+	}
+}
+
+// testSelectorProfile valida el perfil contra páginas guardadas y muestra el resultado de cada una
+func testSelectorProfile(profile *selectorProfile, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("indica al menos un archivo .html o un directorio grabado con --record")
+	}
+
+	pages, err := loadSelectorTestPages(paths)
+	if err != nil {
+		return err
+	}
+
+	if len(pages) == 0 {
+		return fmt.Errorf("no se encontraron páginas de AnimeFLV para probar")
+	}
+
+	kindLabels := map[string]string{
+		"search":    "búsqueda",
+		"episodes":  "episodios",
+		"downloads": "enlaces",
+		"latest":    "últimos",
+	}
+
+	failed := 0
+	for _, page := range pages {
+		counts, ok, err := testSelectorPage(profile, page)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", page.Name, err)
+			failed++
+			continue
+		}
+
+		status := "✅"
+		if !ok {
+			status = "❌"
+			failed++
+		}
+
+		var parts []string
+		for _, kind := range []string{"search", "episodes", "downloads", "latest"} {
+			if page.Kind == "" || page.Kind == kind {
+				parts = append(parts, fmt.Sprintf("%s: %d", kindLabels[kind], counts[kind]))
+			}
+		}
+		fmt.Printf("%s %s (%s)\n", status, page.Name, strings.Join(parts, ", "))
+	}
+
+	fmt.Printf("\n📊 %d páginas probadas, %d con errores\n", len(pages), failed)
+
+	if failed > 0 {
+		return fmt.Errorf("el perfil de selectores no funciona en %d páginas", failed)
+	}
+
+	return nil
+}
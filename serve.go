@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// defaultServeInterval intervalo entre syncs por defecto del modo servicio
+const defaultServeInterval = time.Hour
+
+// errSyncLocked otro proceso está sincronizando la misma lista
+var errSyncLocked = errors.New("ya hay un sync en curso")
+
+// syncLock lock entre procesos sobre la lista de seguimiento (lock del sistema sobre un archivo)
+type syncLock struct {
+	file *os.File
+}
+
+// acquireSyncLock toma el lock o devuelve errSyncLocked si otro proceso lo tiene.
+// El sistema libera el lock cuando el proceso termina, aunque sea por una segunda señal o un kill.
+func acquireSyncLock(file string) (*syncLock, error) {
+	if dir := filepath.Dir(file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creando directorio del lock: %v", err)
+		}
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo lock: %v", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		owner, _ := os.ReadFile(file)
+		f.Close()
+		if errors.Is(err, errSyncLocked) {
+			return nil, fmt.Errorf("%w (pid y inicio: %s; lock: %s)", errSyncLocked, strings.TrimSpace(string(owner)), file)
+		}
+		return nil, fmt.Errorf("error tomando lock: %v", err)
+	}
+
+	// El contenido es solo informativo: quién tiene el lock y desde cuándo
+	f.Truncate(0)
+	fmt.Fprintf(f, "%d %s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+
+	return &syncLock{file: f}, nil
+}
+
+// Release libera el lock. El archivo no se borra: otro proceso puede estar esperando sobre él.
+func (l *syncLock) Release() {
+	l.file.Truncate(0)
+	l.file.Close()
+}
+
+// serveOptions configuración del modo servicio
+type serveOptions struct {
+	Schedule      schedule
+	Jitter        time.Duration // retraso aleatorio agregado a cada ejecución
+	Now           bool          // sincronizar al iniciar, sin esperar al primer horario
+	WatchlistFile string
+	OutputDir     string
+}
+
+// nextRun calcula la próxima ejecución con el retraso aleatorio
+func (o serveOptions) nextRun(after time.Time) time.Time {
+	next := o.Schedule.Next(after)
+	if o.Jitter > 0 && !next.IsZero() {
+		next = next.Add(rand.N(o.Jitter))
+	}
+
+	return next
+}
+
+// serve ejecuta syncs según el horario hasta que se cancele ctx.
+// Los syncs nunca se solapan: el siguiente horario se calcula cuando termina el anterior,
+// y el lock evita chocar con un `sync` manual u otra instancia sobre la misma lista.
+func serve(ctx context.Context, options serveOptions) error {
+	runNow := options.Now
+
+	for {
+		if !runNow {
+			next := options.nextRun(time.Now())
+			if next.IsZero() {
+				return fmt.Errorf("el horario no tiene próximas ejecuciones")
+			}
+			log.Printf("⏰ Próximo sync: %s", next.Format("2006-01-02 15:04:05"))
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Println("👋 Servicio detenido")
+				return nil
+			case <-timer.C:
+			}
+		}
+		runNow = false
+
+		log.Println("🔄 Iniciando sync")
+		start := time.Now()
+
+		results, err := runWatchlistSync(ctx, options.WatchlistFile, options.OutputDir)
+		switch {
+		case errors.Is(err, errSyncLocked):
+			log.Printf("⏭️  Sync omitido: %v", err)
+		case err != nil:
+			log.Printf("❌ Sync fallido: %v", err)
+			notifyRunFailure(err)
+		default:
+			log.Printf("✅ Sync completado en %s (%d animes)", time.Since(start).Round(time.Second), len(results))
+		}
+
+		if ctx.Err() != nil {
+			log.Println("👋 Servicio detenido")
+			return nil
+		}
+	}
+}
+
+// runServe implementa el comando `serve`: sincroniza la lista de seguimiento periódicamente
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	interval := fs.Duration("interval", defaultServeInterval, "Intervalo entre syncs (ej. 30m, 6h)")
+	cronExpr := fs.String("cron", "", "Horario en formato cron, en lugar de --interval (ej. \"0 */6 * * *\", @daily)")
+	jitter := fs.Duration("jitter", 0, "Retraso aleatorio máximo agregado a cada sync (ej. 5m)")
+	now := fs.Bool("now", true, "Sincronizar al iniciar, sin esperar al primer horario")
+	watchlistFile := fs.String("watchlist", defaultWatchlistFile(), "Archivo de la lista de seguimiento")
+	outputDir := fs.String("output", "", "Directorio de las exportaciones nuevas (por defecto el actual)")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	registerNotifyFlags(fs)
+	fs.Parse(args)
+
+	options := serveOptions{
+		Schedule:      intervalSchedule(*interval),
+		Jitter:        *jitter,
+		Now:           *now,
+		WatchlistFile: *watchlistFile,
+		OutputDir:     *outputDir,
+	}
+
+	if *cronExpr != "" {
+		cron, err := parseCron(*cronExpr)
+		if err != nil {
+			return err
+		}
+		options.Schedule = cron
+	} else if *interval < time.Minute {
+		return fmt.Errorf("el intervalo mínimo es 1m")
+	}
+
+	if *jitter < 0 {
+		return fmt.Errorf("--jitter no puede ser negativo")
+	}
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	if err := applyNotifyFlags(); err != nil {
+		return err
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+	selectors = profile
+
+	// SIGINT/SIGTERM: termina el anime en curso, libera el lock y sale; una segunda señal corta de inmediato
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if *cronExpr != "" {
+		log.Printf("🚀 Servicio iniciado, horario cron: %s", *cronExpr)
+	} else {
+		log.Printf("🚀 Servicio iniciado, sync cada %s", *interval)
+	}
+
+	return serve(ctx, options)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// videosVarRegex extrae la variable `videos` con los servidores de streaming del episodio
+var videosVarRegex = regexp.MustCompile(`var\s+videos\s*=\s*(\{.*?\});`)
+
+// streamtapeLinkRegex captura el fragmento de JavaScript que arma el enlace de Streamtape
+var streamtapeLinkRegex = regexp.MustCompile(`getElementById\(\s*['"]robotlink['"]\s*\)\.innerHTML\s*=\s*['"]([^'"]+)['"]\s*\+\s*\(?\s*['"]([^'"]+)['"]\s*\)?((?:\.substring\(\s*\d+\s*(?:,\s*\d+\s*)?\))*)`)
+
+// substringCallRegex captura cada llamada a substring con sus argumentos
+var substringCallRegex = regexp.MustCompile(`\.substring\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+
+// ogTitleRegex extrae el nombre del archivo de las etiquetas og:title
+var ogTitleRegex = regexp.MustCompile(`<meta\s+(?:name|property)=["']og:title["']\s+content=["']([^"']+)["']`)
+
+// yourUploadFileRegex extrae el enlace del video desde la configuración de jwplayer
+var yourUploadFileRegex = regexp.MustCompile(`file\s*:\s*['"]([^'"]+\.mp4[^'"]*)['"]`)
+
+// ogVideoRegex extrae el enlace del video de la etiqueta og:video
+var ogVideoRegex = regexp.MustCompile(`<meta\s+property=["']og:video["']\s+content=["']([^"']+)["']`)
+
+// packedScriptRegex captura los argumentos de un script empaquetado con P.A.C.K.E.R.
+var packedScriptRegex = regexp.MustCompile(`\}\s*\('(.*)',\s*(\d+),\s*(\d+),\s*'(.*?)'\.split\('\|'\)`)
+
+// packedWordRegex captura cada palabra del script empaquetado
+var packedWordRegex = regexp.MustCompile(`\b\w+\b`)
+
+// hlsSourceRegex extrae el playlist m3u8 de la configuración del reproductor
+var hlsSourceRegex = regexp.MustCompile(`file\s*:\s*["']([^"']+\.m3u8[^"']*)["']`)
+
+// streamingServer representa un servidor de la variable `videos` de AnimeFLV
+type streamingServer struct {
+	Server string `json:"server"`
+	Title  string `json:"title"`
+	Code   string `json:"code"`
+	URL    string `json:"url"`
+}
+
+// extractStreamingServers obtiene los servidores de streaming con resolvedor disponible
+func extractStreamingServers(doc *goquery.Document) []Download {
+	var downloads []Download
+
+	scripts := doc.Find("script").Text()
+	matches := videosVarRegex.FindStringSubmatch(scripts)
+	if len(matches) < 2 {
+		return downloads
+	}
+
+	var videos map[string][]streamingServer
+	if err := json.Unmarshal([]byte(matches[1]), &videos); err != nil {
+		return downloads
+	}
+
+	// Recorrer los idiomas en orden estable (SUB, LAT)
+	for _, language := range []string{"SUB", "LAT"} {
+		for _, server := range videos[language] {
+			link := server.Code
+			if link == "" {
+				link = server.URL
+			}
+
+			if findResolver(link) == nil {
+				continue
+			}
+
+			downloads = append(downloads, Download{
+				ProviderName: server.Title,
+				DownloadURL:  link,
+			})
+		}
+	}
+
+	return downloads
+}
+
+// appendUniqueDownloads añade descargas nuevas evitando enlaces repetidos
+func appendUniqueDownloads(downloads []Download, extra []Download) []Download {
+	seen := make(map[string]bool)
+	for _, download := range downloads {
+		seen[download.DownloadURL] = true
+	}
+
+	for _, download := range extra {
+		if !seen[download.DownloadURL] {
+			seen[download.DownloadURL] = true
+			downloads = append(downloads, download)
+		}
+	}
+
+	return downloads
+}
+
+// resolveStreamtape evalúa el JavaScript ofuscado de Streamtape para obtener el MP4
+func resolveStreamtape(pageURL string) (*Resolved, error) {
+	// La página /v/ y el embed /e/ contienen el mismo script
+	pageURL = strings.Replace(pageURL, "/v/", "/e/", 1)
+
+	body, _, err := fetchProviderPage(pageURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStreamtapePage(body, pageURL)
+}
+
+// parseStreamtapePage construye el enlace directo a partir del HTML de Streamtape
+func parseStreamtapePage(html, pageURL string) (*Resolved, error) {
+	matches := streamtapeLinkRegex.FindStringSubmatch(html)
+	if len(matches) < 4 {
+		return nil, fmt.Errorf("script de enlace de Streamtape no encontrado")
+	}
+
+	token := matches[2]
+	for _, call := range substringCallRegex.FindAllStringSubmatch(matches[3], -1) {
+		start, _ := strconv.Atoi(call[1])
+		end := len(token)
+		if call[2] != "" {
+			end, _ = strconv.Atoi(call[2])
+		}
+		token = jsSubstring(token, start, end)
+	}
+
+	directURL := matches[1] + token
+	if strings.HasPrefix(directURL, "//") {
+		directURL = "https:" + directURL
+	} else if strings.HasPrefix(directURL, "/") {
+		directURL = "https:/" + directURL
+	}
+
+	resolved := &Resolved{
+		URL:     directURL + "&stream=1",
+		Headers: map[string]string{"Referer": pageURL},
+	}
+
+	if title := ogTitleRegex.FindStringSubmatch(html); len(title) > 1 {
+		resolved.FileName = strings.TrimSpace(title[1])
+	}
+
+	return resolved, nil
+}
+
+// jsSubstring replica String.prototype.substring de JavaScript
+func jsSubstring(text string, start, end int) string {
+	start = min(max(start, 0), len(text))
+	end = min(max(end, 0), len(text))
+	if start > end {
+		start, end = end, start
+	}
+
+	return text[start:end]
+}
+
+// resolveYourUpload obtiene el MP4 de la configuración del reproductor de YourUpload
+func resolveYourUpload(pageURL string) (*Resolved, error) {
+	// Solo el embed incluye la configuración del reproductor
+	pageURL = strings.Replace(pageURL, "/watch/", "/embed/", 1)
+
+	body, _, err := fetchProviderPage(pageURL, urlBase+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseYourUploadPage(body)
+}
+
+// parseYourUploadPage extrae el enlace del video desde el HTML de YourUpload
+func parseYourUploadPage(html string) (*Resolved, error) {
+	directURL := ""
+	if matches := yourUploadFileRegex.FindStringSubmatch(html); len(matches) > 1 {
+		directURL = matches[1]
+	} else if matches := ogVideoRegex.FindStringSubmatch(html); len(matches) > 1 {
+		directURL = matches[1]
+	}
+
+	if directURL == "" {
+		return nil, fmt.Errorf("enlace de video de YourUpload no encontrado")
+	}
+
+	resolved := &Resolved{
+		URL: directURL,
+		// El servidor de YourUpload rechaza peticiones sin Referer propio
+		Headers: map[string]string{"Referer": "https://www.yourupload.com/"},
+	}
+
+	if title := ogTitleRegex.FindStringSubmatch(html); len(title) > 1 {
+		resolved.FileName = strings.TrimSpace(title[1])
+	}
+
+	return resolved, nil
+}
+
+// unpackScript desempaqueta un script ofuscado con P.A.C.K.E.R. (eval(function(p,a,c,k,e,d)...))
+func unpackScript(html string) (string, error) {
+	matches := packedScriptRegex.FindStringSubmatch(html)
+	if len(matches) < 5 {
+		return "", fmt.Errorf("script empaquetado no encontrado")
+	}
+
+	payload := strings.ReplaceAll(matches[1], `\'`, `'`)
+	base, _ := strconv.Atoi(matches[2])
+	words := strings.Split(matches[4], "|")
+
+	unpacked := packedWordRegex.ReplaceAllStringFunc(payload, func(word string) string {
+		index, err := parseBaseN(word, base)
+		if err != nil || index >= len(words) || words[index] == "" {
+			return word
+		}
+		return words[index]
+	})
+
+	return unpacked, nil
+}
+
+// parseBaseN interpreta un número en base hasta 62 con el alfabeto 0-9a-zA-Z
+func parseBaseN(word string, base int) (int, error) {
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	if base < 2 || base > len(alphabet) {
+		return 0, fmt.Errorf("base no soportada: %d", base)
+	}
+
+	value := 0
+	for _, char := range word {
+		digit := strings.IndexRune(alphabet[:base], char)
+		if digit < 0 {
+			return 0, fmt.Errorf("dígito inválido: %c", char)
+		}
+		value = value*base + digit
+	}
+
+	return value, nil
+}
+
+// resolveStreamWish obtiene el playlist HLS del reproductor empaquetado de StreamWish
+func resolveStreamWish(pageURL string) (*Resolved, error) {
+	body, finalURL, err := fetchProviderPage(pageURL, urlBase+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStreamWishPage(body, finalURL.Scheme+"://"+finalURL.Host+"/")
+}
+
+// parseStreamWishPage extrae el playlist m3u8 del HTML de StreamWish
+func parseStreamWishPage(html, origin string) (*Resolved, error) {
+	// Algunas páginas dejan la configuración sin empaquetar
+	script := html
+	if unpacked, err := unpackScript(html); err == nil {
+		script = unpacked
+	}
+
+	matches := hlsSourceRegex.FindStringSubmatch(script)
+	if len(matches) < 2 {
+		return nil, fmt.Errorf("playlist m3u8 de StreamWish no encontrado")
+	}
+
+	return &Resolved{
+		URL: matches[1],
+		Headers: map[string]string{
+			"Referer": origin,
+			"Origin":  strings.TrimSuffix(origin, "/"),
+		},
+	}, nil
+}
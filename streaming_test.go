@@ -0,0 +1,247 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStreamtapePage(t *testing.T) {
+	pageURL := "https://streamtape.com/e/Lk3QxRz"
+
+	tests := []struct {
+		fixture  string
+		url      string
+		fileName string
+		wantErr  bool
+	}{
+		{
+			// El enlace de robotlink se arma con su propio token; ideoolink es un señuelo
+			fixture:  "streamtape.html",
+			url:      "https://streamtape.com/get_video?id=Lk3QxRz&expires=1760000000&ip=FRPbKRWQFxSHDN&token=Q9vL8p_T0k&stream=1",
+			fileName: "Dandadan_01.mp4",
+		},
+		{
+			fixture: "streamtape-nolink.html",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			resolved, err := parseStreamtapePage(loadFixture(t, "streaming", tt.fixture), pageURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba error, se obtuvo %+v", resolved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			if resolved.URL != tt.url {
+				t.Errorf("URL = %q, se esperaba %q", resolved.URL, tt.url)
+			}
+			if resolved.FileName != tt.fileName {
+				t.Errorf("FileName = %q, se esperaba %q", resolved.FileName, tt.fileName)
+			}
+			if resolved.Headers["Referer"] != pageURL {
+				t.Errorf("Referer = %q, se esperaba %q", resolved.Headers["Referer"], pageURL)
+			}
+		})
+	}
+}
+
+func TestJSSubstring(t *testing.T) {
+	tests := []struct {
+		text       string
+		start, end int
+		want       string
+	}{
+		{"abcdef", 2, 6, "cdef"},
+		{"abcdef", 1, 3, "bc"},
+		{"abcdef", 4, 1, "bcd"}, // los índices invertidos se intercambian
+		{"abcdef", -3, 2, "ab"}, // los negativos cuentan como 0
+		{"abcdef", 3, 100, "def"},
+		{"abcdef", 10, 20, ""},
+	}
+
+	for _, tt := range tests {
+		if got := jsSubstring(tt.text, tt.start, tt.end); got != tt.want {
+			t.Errorf("jsSubstring(%q, %d, %d) = %q, se esperaba %q", tt.text, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestParseYourUploadPage(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		url      string
+		fileName string
+		wantErr  bool
+	}{
+		{
+			// La configuración del reproductor tiene prioridad sobre og:video
+			fixture:  "yourupload.html",
+			url:      "https://vidcache.net:8161/a20241018jUmUn3nR8XZ/video.mp4?token=Qm9yP1",
+			fileName: "Frieren_12.mp4",
+		},
+		{
+			fixture:  "yourupload-og.html",
+			url:      "https://vidcache.net:8161/a20241025Xk2/video.mp4",
+			fileName: "Frieren_13.mp4",
+		},
+		{
+			fixture: "yourupload-removed.html",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			resolved, err := parseYourUploadPage(loadFixture(t, "streaming", tt.fixture))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba error, se obtuvo %+v", resolved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			if resolved.URL != tt.url {
+				t.Errorf("URL = %q, se esperaba %q", resolved.URL, tt.url)
+			}
+			if resolved.FileName != tt.fileName {
+				t.Errorf("FileName = %q, se esperaba %q", resolved.FileName, tt.fileName)
+			}
+			if resolved.Headers["Referer"] != "https://www.yourupload.com/" {
+				t.Errorf("Referer = %q", resolved.Headers["Referer"])
+			}
+		})
+	}
+}
+
+func TestUnpackScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		packed  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "base 36 con comillas escapadas",
+			packed: `eval(function(p,a,c,k,e,d){return p}('0("1").2({3:\'4\'});',36,5,'jwplayer|vplayer|setup|preload|auto'.split('|')))`,
+			want:   `jwplayer("vplayer").setup({preload:'auto'});`,
+		},
+		{
+			// Las posiciones vacías conservan la palabra original (el packer las deja así)
+			name:   "palabras vacías",
+			packed: `eval(function(p,a,c,k,e,d){return p}('0 1 2',10,3,'var||x'.split('|')))`,
+			want:   `var 1 x`,
+		},
+		{
+			// Los índices 0-60 son relleno: Z es 61 y 10 es 62
+			name:   "base 62",
+			packed: `eval(function(p,a,c,k,e,d){return p}('Z+10',62,63,'` + strings.Repeat("w|", 61) + `last|after'.split('|')))`,
+			want:   `last+after`,
+		},
+		{
+			name:    "sin script empaquetado",
+			packed:  `<script>var x = 1;</script>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unpackScript(tt.packed)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba error, se obtuvo %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unpackScript = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBaseN(t *testing.T) {
+	tests := []struct {
+		word    string
+		base    int
+		want    int
+		wantErr bool
+	}{
+		{"0", 36, 0, false},
+		{"z", 36, 35, false},
+		{"10", 36, 36, false},
+		{"Z", 62, 61, false},
+		{"1a", 62, 72, false},
+		{"g", 16, 0, true}, // fuera del alfabeto de la base
+		{"1", 63, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBaseN(tt.word, tt.base)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBaseN(%q, %d) error = %v, se esperaba error: %v", tt.word, tt.base, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseBaseN(%q, %d) = %d, se esperaba %d", tt.word, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestParseStreamWishPage(t *testing.T) {
+	origin := "https://streamwish.to/"
+
+	tests := []struct {
+		fixture string
+		url     string
+		wantErr bool
+	}{
+		{
+			fixture: "streamwish-packed.html",
+			url:     "https://cdn.example.com/hls/master.m3u8?t=AbC12",
+		},
+		{
+			fixture: "streamwish-plain.html",
+			url:     "https://cdn.example.com/hls/frieren-13/master.m3u8",
+		},
+		{
+			fixture: "streamwish-nosource.html",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			resolved, err := parseStreamWishPage(loadFixture(t, "streaming", tt.fixture), origin)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba error, se obtuvo %+v", resolved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			if resolved.URL != tt.url {
+				t.Errorf("URL = %q, se esperaba %q", resolved.URL, tt.url)
+			}
+			if resolved.Headers["Referer"] != origin || resolved.Headers["Origin"] != "https://streamwish.to" {
+				t.Errorf("Headers = %v", resolved.Headers)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxUnwrapDepth límite de envoltorios anidados que se desenvuelven
+const maxUnwrapDepth = 5
+
+// wrapperParams parámetros de query donde los acortadores guardan el enlace real
+var wrapperParams = []string{"s", "url", "u", "link", "target", "dest", "go", "r", "href"}
+
+// redirectorHosts acortadores sin enlace embebido que requieren seguir la redirección
+var redirectorHosts = []string{
+	"ouo.io",
+	"ouo.press",
+	"bit.ly",
+	"tinyurl.com",
+	"shorturl.at",
+	"cutt.ly",
+	"is.gd",
+}
+
+// isRedirectorHost indica si el host pertenece a un acortador conocido
+func isRedirectorHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range redirectorHosts {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeEmbeddedURL interpreta el valor de un parámetro como URL, directa o en base64
+func decodeEmbeddedURL(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if isHTTPURL(value) {
+		return value, true
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil && isHTTPURL(string(decoded)) {
+			return string(decoded), true
+		}
+	}
+
+	return "", false
+}
+
+// isHTTPURL indica si el texto es una URL absoluta http o https
+func isHTTPURL(text string) bool {
+	u, err := url.Parse(text)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isPlainQueryURL indica si el parámetro de la query trae una URL sin codificar (ni %-encoding ni base64)
+func isPlainQueryURL(rawQuery, param string) bool {
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if value, found := strings.CutPrefix(pair, param+"="); found {
+			return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+		}
+	}
+
+	return false
+}
+
+// unwrapLink recupera el enlace real de un enlace envuelto por un acortador o redirector
+func unwrapLink(link string) string {
+	for range maxUnwrapDepth {
+		u, err := url.Parse(link)
+		if err != nil || !isHTTPURL(link) {
+			return link
+		}
+
+		// Solo se desenvuelven acortadores: en otros hosts un parámetro url= o s= es parte del enlace
+		if !isRedirectorHost(u.Hostname()) {
+			return link
+		}
+
+		// Enlace embebido en un parámetro de query (ej. ouo.io/s/xxx?s=https://...)
+		embedded := ""
+		query := u.Query()
+		for _, param := range wrapperParams {
+			if target, ok := decodeEmbeddedURL(query.Get(param)); ok {
+				embedded = target
+
+				// Un enlace sin codificar pierde su #fragmento (ej. la clave de MEGA) en el del acortador
+				if u.Fragment != "" && isPlainQueryURL(u.RawQuery, param) {
+					embedded += "#" + u.Fragment
+				}
+				break
+			}
+		}
+
+		if embedded != "" {
+			link = embedded
+			continue
+		}
+
+		// Acortador sin parámetro: seguir la redirección
+		target, err := followRedirect(link)
+		if err != nil || target == link {
+			return link
+		}
+		link = target
+	}
+
+	return link
+}
+
+// followRedirect obtiene el destino de una redirección sin descargar el contenido
+func followRedirect(link string) (string, error) {
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: httpTransport,
+		Jar:       sessionJar,
+		// No seguir automáticamente: solo interesa la cabecera Location
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := newBrowserRequest("HEAD", link, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := doWithRetry(client, req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	location, err := resp.Location()
+	if err != nil {
+		return link, nil
+	}
+
+	// Solo se aceptan destinos http(s) para evitar esquemas inesperados
+	if !isHTTPURL(location.String()) {
+		return link, nil
+	}
+
+	return location.String(), nil
+}
+
+// unwrapDownloads normaliza los enlaces de una lista de descargas
+func unwrapDownloads(downloads []Download) {
+	for i := range downloads {
+		downloads[i].DownloadURL = unwrapLink(downloads[i].DownloadURL)
+	}
+}
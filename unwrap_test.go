@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestUnwrapLink(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{
+			name: "acortador con enlace en la query",
+			link: "http://ouo.io/s/y0d65LCP?s=https://mega.nz/file/abc#key",
+			want: "https://mega.nz/file/abc#key",
+		},
+		{
+			name: "acortador con enlace codificado",
+			link: "http://ouo.io/s/y0d65LCP?s=https%3A%2F%2Fmega.nz%2Ffile%2Fabc%23key",
+			want: "https://mega.nz/file/abc#key",
+		},
+		{
+			name: "acortador con enlace en base64",
+			link: "https://ouo.press/go?url=aHR0cHM6Ly93d3cubWVkaWFmaXJlLmNvbS9maWxlL3h5ei9maWxlLm1wNA==",
+			want: "https://www.mediafire.com/file/xyz/file.mp4",
+		},
+		{
+			name: "proveedor con parámetro url propio",
+			link: "https://streamtape.com/v/abc?url=https://example.com/otra",
+			want: "https://streamtape.com/v/abc?url=https://example.com/otra",
+		},
+		{
+			name: "proveedor con parámetro s propio",
+			link: "https://www.yourupload.com/watch/abc?s=aHR0cHM6Ly9leGFtcGxlLmNvbQ==",
+			want: "https://www.yourupload.com/watch/abc?s=aHR0cHM6Ly9leGFtcGxlLmNvbQ==",
+		},
+		{
+			name: "enlace relativo",
+			link: "/ver/naruto-1",
+			want: "/ver/naruto-1",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := unwrapLink(tt.link); got != tt.want {
+			t.Errorf("%s: unwrapLink(%q) = %q; se esperaba %q", tt.name, tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// watchEntry anime seguido con los episodios ya procesados
+type watchEntry struct {
+	Slug     string   `json:"slug"`
+	Title    string   `json:"title"`
+	Link     string   `json:"link"`
+	Added    string   `json:"added"`
+	LastSync string   `json:"last_sync,omitempty"`
+	Export   string   `json:"export,omitempty"` // archivo de texto de la exportación
+	Seen     []string `json:"seen,omitempty"`   // enlaces de los episodios ya procesados
+}
+
+// watchlist lista de animes seguidos, guardada en JSON
+type watchlist struct {
+	file    string
+	Entries []*watchEntry `json:"entries"`
+}
+
+// defaultWatchlistFile ubicación por defecto de la lista (directorio de configuración del usuario)
+func defaultWatchlistFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "watchlist.json"
+	}
+
+	return filepath.Join(dir, "animeflv-downloader", "watchlist.json")
+}
+
+// loadWatchlist lee la lista; si el archivo no existe devuelve una lista vacía
+func loadWatchlist(file string) (*watchlist, error) {
+	list := &watchlist{file: file}
+
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la lista de seguimiento: %v", err)
+	}
+
+	if err := json.Unmarshal(content, list); err != nil {
+		return nil, fmt.Errorf("error parseando la lista de seguimiento: %v", err)
+	}
+
+	return list, nil
+}
+
+// Save guarda la lista de forma atómica
+func (w *watchlist) Save() error {
+	content, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+
+	if dir := filepath.Dir(w.file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creando directorio de la lista: %v", err)
+		}
+	}
+
+	// Escritura atómica: si se corta el programa a mitad de un sync no se pierde lo ya visto
+	tmpFile := w.file + ".tmp"
+	if err := os.WriteFile(tmpFile, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("error escribiendo la lista de seguimiento: %v", err)
+	}
+
+	return os.Rename(tmpFile, w.file)
+}
+
+// find busca un anime de la lista por slug
+func (w *watchlist) find(slug string) *watchEntry {
+	for _, entry := range w.Entries {
+		if entry.Slug == slug {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// remove quita un anime de la lista; devuelve false si no estaba
+func (w *watchlist) remove(slug string) bool {
+	for i, entry := range w.Entries {
+		if entry.Slug == slug {
+			w.Entries = append(w.Entries[:i], w.Entries[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// seen indica si el episodio ya fue procesado
+func (e *watchEntry) seen(episodeLink string) bool {
+	for _, link := range e.Seen {
+		if link == episodeLink {
+			return true
+		}
+	}
+
+	return false
+}
+
+// slugFromArg obtiene el slug a partir de un slug, una ruta /anime/slug o una URL
+func slugFromArg(arg string) (string, error) {
+	animeLink, err := animeLinkFromArg(arg)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(animeLink, "/anime/"), nil
+}
+
+// syncResult resultado de sincronizar un anime de la lista
+type syncResult struct {
+	Slug        string          `json:"slug"`
+	Title       string          `json:"title"`
+	Link        string          `json:"link"`
+	File        string          `json:"file,omitempty"`
+	NewEpisodes []ExportEpisode `json:"new_episodes,omitempty"`
+	Warning     string          `json:"warning,omitempty"` // aviso no fatal: episodios nuevos que quedaron sin enlaces
+	Err         error           `json:"-"`
+}
+
+// syncWatchEntry busca episodios nuevos del anime, obtiene solo sus enlaces y actualiza la exportación.
+// Los episodios sin enlaces no se marcan como vistos, así se reintentan en el siguiente sync.
+func syncWatchEntry(entry *watchEntry, outputDir string, verbose bool) syncResult {
+	result := syncResult{Slug: entry.Slug, Title: entry.Title, Link: entry.Link}
+
+	// La lista de episodios cambia con cada estreno: no se reutiliza la copia en caché
+	pageCache.Delete(urlBase + entry.Link)
+
+	details, episodesList, err := getLinksEpisodes(entry.Title, entry.Link, verbose)
+	if err != nil {
+		result.Err = fmt.Errorf("error obteniendo episodios: %w", err)
+		return result
+	}
+
+	var newEpisodes []Episode
+	for _, episode := range episodesList {
+		if !entry.seen(episode.Link) {
+			newEpisodes = append(newEpisodes, episode)
+		}
+	}
+
+	entry.LastSync = time.Now().Format("2006-01-02 15:04:05")
+	if len(newEpisodes) == 0 {
+		return result
+	}
+
+	if verbose {
+		fmt.Printf("🆕 %d episodios nuevos\n", len(newEpisodes))
+	}
+	allDownloads := fetchAllDownloads(newEpisodes, verbose)
+
+	// Partir de la exportación anterior (si existe) y agregar o reemplazar los episodios nuevos
+	baseName := filepath.Join(outputDir, sanitizeFilename(entry.Title))
+	if entry.Export != "" {
+		baseName = strings.TrimSuffix(entry.Export, ".txt")
+	}
+
+	export := ExportFile{Anime: entry.Title}
+	if _, err := os.Stat(baseName + ".json"); err == nil {
+		export, err = loadExport(baseName + ".json")
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	var missing []string
+	for _, episode := range newEpisodes {
+		downloads := allDownloads[episode.Link]
+		if len(downloads) == 0 {
+			missing = append(missing, episode.Name)
+			continue
+		}
+
+		added := ExportEpisode{Episode: episode, Downloads: downloads}
+		result.NewEpisodes = append(result.NewEpisodes, added)
+
+		replaced := false
+		for i := range export.Episodes {
+			if export.Episodes[i].Link == episode.Link {
+				export.Episodes[i] = added
+				replaced = true
+			}
+		}
+		if !replaced {
+			export.Episodes = append(export.Episodes, added)
+		}
+	}
+
+	if len(result.NewEpisodes) == 0 {
+		result.Err = fmt.Errorf("no se obtuvieron enlaces de los %d episodios nuevos", len(newEpisodes))
+		return result
+	}
+	if len(missing) > 0 {
+		result.Warning = fmt.Sprintf("%d episodios sin enlaces, se reintentan en el próximo sync: %s", len(missing), strings.Join(missing, ", "))
+	}
+
+	// Orden por número de episodio; los que no tienen número quedan al final
+	sort.SliceStable(export.Episodes, func(a, b int) bool {
+		numberA, numberB := parseEpisodeNumber(export.Episodes[a].Name), parseEpisodeNumber(export.Episodes[b].Name)
+		if (numberA == 0) != (numberB == 0) {
+			return numberA != 0
+		}
+		return numberA < numberB
+	})
+
+	export.Details = details
+	export.Generated = time.Now().Format("2006-01-02 15:04:05")
+
+	result.File, err = writeExportFiles(baseName, export)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	entry.Export = result.File
+
+	// Se marcan como vistos solo cuando ya quedaron guardados en la exportación
+	for _, episode := range result.NewEpisodes {
+		entry.Seen = append(entry.Seen, episode.Link)
+	}
+
+	return result
+}
+
+// syncWatchlist sincroniza todos los animes de la lista y la guarda después de cada uno.
+// Si se cancela ctx termina el anime en curso y no empieza los siguientes.
+func syncWatchlist(ctx context.Context, list *watchlist, outputDir string, verbose bool) ([]syncResult, error) {
+	results := make([]syncResult, 0, len(list.Entries))
+
+	for i, entry := range list.Entries {
+		if ctx.Err() != nil {
+			fmt.Printf("\n⏹️  Sync interrumpido: quedan %d animes sin revisar\n", len(list.Entries)-i)
+			break
+		}
+
+		fmt.Printf("\n🔄 [%d/%d] %s\n", i+1, len(list.Entries), entry.Title)
+
+		result := syncWatchEntry(entry, outputDir, verbose)
+		results = append(results, result)
+
+		switch {
+		case result.Err != nil:
+			fmt.Printf("❌ %s: %v\n", entry.Title, result.Err)
+		case len(result.NewEpisodes) == 0:
+			fmt.Printf("✔️  %s: sin episodios nuevos\n", entry.Title)
+		default:
+			fmt.Printf("✅ %s: %d episodios nuevos → %s\n", entry.Title, len(result.NewEpisodes), result.File)
+		}
+		if result.Warning != "" {
+			fmt.Printf("⚠️  %s: %s\n", entry.Title, result.Warning)
+		}
+
+		if err := list.Save(); err != nil {
+			return results, err
+		}
+
+		// Se notifica recién con la lista guardada: si falla el guardado el próximo sync repite los episodios
+		notifySyncResult(result)
+	}
+
+	return results, nil
+}
+
+// printSyncSummary muestra el resumen del sync con los episodios nuevos, los avisos y los fallos
+func printSyncSummary(results []syncResult) {
+	newEpisodes, warned, failed := 0, 0, 0
+	for _, result := range results {
+		newEpisodes += len(result.NewEpisodes)
+		if result.Warning != "" {
+			warned++
+		}
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Printf("\n📊 Resumen: %d animes, 🆕 %d episodios nuevos, ⚠️  %d con avisos, ❌ %d con errores\n", len(results), newEpisodes, warned, failed)
+
+	for _, result := range results {
+		for _, episode := range result.NewEpisodes {
+			fmt.Printf("   🆕 %s - %s (%d enlaces)\n", result.Title, episode.Name, len(episode.Downloads))
+		}
+		if result.Warning != "" {
+			fmt.Printf("   ⚠️  %s: %s\n", result.Title, result.Warning)
+		}
+	}
+}
+
+// runWatchlist implementa el comando `watchlist`: agrega, quita o lista animes seguidos
+func runWatchlist(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: watchlist add [--all] slug... | watchlist remove slug... | watchlist list")
+	}
+
+	fs := flag.NewFlagSet("watchlist "+args[0], flag.ExitOnError)
+	watchlistFile := fs.String("watchlist", defaultWatchlistFile(), "Archivo de la lista de seguimiento")
+	all := fs.Bool("all", false, "No marcar como vistos los episodios ya publicados (el primer sync los procesa todos)")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	fs.Parse(args[1:])
+
+	// Agregar o quitar durante un sync se perdería al guardar la lista del sync
+	if args[0] == "add" || args[0] == "remove" {
+		lock, err := acquireSyncLock(*watchlistFile + ".lock")
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	list, err := loadWatchlist(*watchlistFile)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "add":
+		if fs.NArg() == 0 {
+			return fmt.Errorf("indica al menos un slug")
+		}
+
+		if err := applyNetworkFlags(); err != nil {
+			return err
+		}
+
+		profile, err := loadSelectorProfile(*selectorsFile)
+		if err != nil {
+			return err
+		}
+		selectors = profile
+
+		for _, arg := range fs.Args() {
+			slug, err := slugFromArg(arg)
+			if err != nil {
+				return err
+			}
+
+			if list.find(slug) != nil {
+				fmt.Printf("ℹ️  %s ya está en la lista\n", slug)
+				continue
+			}
+
+			details, episodesList, err := getLinksEpisodes(slug, "/anime/"+slug, true)
+			if err != nil {
+				return fmt.Errorf("error obteniendo %s: %w", slug, err)
+			}
+			if details == nil || details.Title == "" {
+				return fmt.Errorf("no se encontró el anime %s", slug)
+			}
+
+			entry := &watchEntry{
+				Slug:  slug,
+				Title: details.Title,
+				Link:  "/anime/" + slug,
+				Added: time.Now().Format("2006-01-02 15:04:05"),
+			}
+			if !*all {
+				for _, episode := range episodesList {
+					entry.Seen = append(entry.Seen, episode.Link)
+				}
+			}
+
+			list.Entries = append(list.Entries, entry)
+			fmt.Printf("➕ %s agregado (%d episodios ya publicados)\n", details.Title, len(entry.Seen))
+		}
+
+		return list.Save()
+
+	case "remove":
+		if fs.NArg() == 0 {
+			return fmt.Errorf("indica al menos un slug")
+		}
+
+		for _, arg := range fs.Args() {
+			slug, err := slugFromArg(arg)
+			if err != nil {
+				return err
+			}
+
+			if list.remove(slug) {
+				fmt.Printf("➖ %s quitado de la lista\n", slug)
+			} else {
+				fmt.Printf("⚠️  %s no está en la lista\n", slug)
+			}
+		}
+
+		return list.Save()
+
+	case "list":
+		if len(list.Entries) == 0 {
+			fmt.Println("La lista de seguimiento está vacía.")
+			return nil
+		}
+
+		for _, entry := range list.Entries {
+			lastSync := entry.LastSync
+			if lastSync == "" {
+				lastSync = "nunca"
+			}
+			fmt.Printf("• %s (%s): %d episodios vistos, último sync: %s\n", entry.Title, entry.Slug, len(entry.Seen), lastSync)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("subcomando desconocido: watchlist %s", args[0])
+	}
+}
+
+// runWatchlistSync sincroniza la lista con el lock tomado, para no pisarse con otro sync en curso
+func runWatchlistSync(ctx context.Context, watchlistFile, outputDir string) ([]syncResult, error) {
+	lock, err := acquireSyncLock(watchlistFile + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	// Se lee en cada sync: la lista puede haber cambiado desde el anterior (watchlist add/remove)
+	list, err := loadWatchlist(watchlistFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Entries) == 0 {
+		return nil, fmt.Errorf("la lista de seguimiento está vacía (usa watchlist add slug)")
+	}
+
+	if outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, fmt.Errorf("error creando directorio: %v", err)
+		}
+	}
+
+	results, err := syncWatchlist(ctx, list, outputDir, true)
+	if err != nil {
+		return results, err
+	}
+
+	printSyncSummary(results)
+	return results, nil
+}
+
+// runSync implementa el comando `sync`: procesa solo los episodios nuevos de los animes seguidos
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	watchlistFile := fs.String("watchlist", defaultWatchlistFile(), "Archivo de la lista de seguimiento")
+	outputDir := fs.String("output", "", "Directorio de las exportaciones nuevas (por defecto el actual)")
+	selectorsFile := fs.String("selectors", "", "Perfil de selectores JSON o YAML a usar en lugar del incluido")
+	registerNetworkFlags(fs)
+	registerNotifyFlags(fs)
+	fs.Parse(args)
+
+	if err := applyNetworkFlags(); err != nil {
+		return err
+	}
+
+	if err := applyNotifyFlags(); err != nil {
+		return err
+	}
+
+	profile, err := loadSelectorProfile(*selectorsFile)
+	if err != nil {
+		return err
+	}
+	selectors = profile
+
+	// Ctrl+C termina el anime en curso y libera el lock; un segundo Ctrl+C corta de inmediato
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	results, err := runWatchlistSync(ctx, *watchlistFile, *outputDir)
+	if err != nil {
+		if !errors.Is(err, errSyncLocked) {
+			notifyRunFailure(err)
+		}
+		return err
+	}
+
+	pageCache.printStats()
+
+	for _, result := range results {
+		if result.Err != nil {
+			return fmt.Errorf("el sync terminó con errores")
+		}
+	}
+
+	return nil
+}
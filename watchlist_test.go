@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncWatchEntryAddsOnlyNewEpisodes(t *testing.T) {
+	animeURL := urlBase + "/anime/test-anime"
+	episode := loadFixture(t, "watchlist", "episode.html")
+
+	fetcher := &fixtureFetcher{
+		pages: map[string]string{
+			animeURL:                      loadFixture(t, "watchlist", "anime-1.html"),
+			urlBase + "/ver/test-anime-1": episode,
+			urlBase + "/ver/test-anime-2": episode,
+			urlBase + "/ver/test-anime-3": episode,
+			urlBase + "/ver/test-anime-4": loadFixture(t, "watchlist", "episode-empty.html"),
+		},
+		requests: make(map[string]int),
+	}
+
+	previous := pageFetcher
+	pageFetcher = &fetchChain{fetchers: []Fetcher{fetcher}}
+	t.Cleanup(func() { pageFetcher = previous })
+
+	outputDir := t.TempDir()
+	entry := &watchEntry{Slug: "test-anime", Title: "Test Anime", Link: "/anime/test-anime"}
+
+	// Primer sync: los dos episodios publicados son nuevos
+	result := syncWatchEntry(entry, outputDir, false)
+	if result.Err != nil {
+		t.Fatalf("primer sync: %v", result.Err)
+	}
+	if len(result.NewEpisodes) != 2 || result.Warning != "" {
+		t.Fatalf("primer sync: %d episodios nuevos, aviso %q; se esperaban 2 sin aviso", len(result.NewEpisodes), result.Warning)
+	}
+
+	// Segundo sync: se publicaron el 3 (con enlaces) y el 4 (todavía sin enlaces)
+	fetcher.pages[animeURL] = loadFixture(t, "watchlist", "anime-2.html")
+
+	result = syncWatchEntry(entry, outputDir, false)
+	if result.Err != nil {
+		t.Fatalf("segundo sync: %v", result.Err)
+	}
+	if len(result.NewEpisodes) != 1 || result.NewEpisodes[0].Link != "/ver/test-anime-3" {
+		t.Fatalf("segundo sync: episodios nuevos %+v; se esperaba solo /ver/test-anime-3", result.NewEpisodes)
+	}
+	if !strings.Contains(result.Warning, "Episodio 4") {
+		t.Errorf("aviso = %q; se esperaba que mencione el Episodio 4", result.Warning)
+	}
+
+	// Los episodios ya procesados no se vuelven a pedir
+	for _, link := range []string{"/ver/test-anime-1", "/ver/test-anime-2", "/ver/test-anime-3"} {
+		if requests := fetcher.requests[urlBase+link]; requests != 1 {
+			t.Errorf("%s pedido %d veces; se esperaba 1", link, requests)
+		}
+	}
+
+	export, err := loadExport(filepath.Join(outputDir, "Test_Anime.json"))
+	if err != nil {
+		t.Fatalf("loadExport: %v", err)
+	}
+
+	want := []string{"Episodio 1", "Episodio 2", "Episodio 3"}
+	if len(export.Episodes) != len(want) {
+		t.Fatalf("%d episodios exportados; se esperaban %d: %+v", len(export.Episodes), len(want), export.Episodes)
+	}
+	for i, name := range want {
+		if export.Episodes[i].Name != name {
+			t.Errorf("episodio %d = %q; se esperaba %q", i, export.Episodes[i].Name, name)
+		}
+	}
+
+	// El episodio sin enlaces queda sin marcar para reintentarlo en el próximo sync
+	if entry.seen("/ver/test-anime-4") || len(entry.Seen) != 3 {
+		t.Errorf("vistos = %v; se esperaban los episodios 1 a 3", entry.Seen)
+	}
+}